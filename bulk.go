@@ -0,0 +1,284 @@
+package redshiftdatasqldriver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mashiike/redshift-data-sql-driver/internal/sqlquote"
+	"github.com/mashiike/redshift-data-sql-driver/redshiftdatacopy"
+)
+
+// BulkRows is the source of the rows of BulkInsert. NextRow returns io.EOF after the last row.
+type BulkRows interface {
+	NextRow() ([]any, error)
+}
+
+type sliceRows struct {
+	rows [][]any
+}
+
+// SliceRows returns BulkRows over rows.
+func SliceRows(rows [][]any) BulkRows {
+	return &sliceRows{rows: rows}
+}
+
+func (r *sliceRows) NextRow() ([]any, error) {
+	if len(r.rows) == 0 {
+		return nil, io.EOF
+	}
+	row := r.rows[0]
+	r.rows = r.rows[1:]
+	return row, nil
+}
+
+// ObjectWriter stores the files staged for COPY, for example in S3.
+type ObjectWriter interface {
+	// WriteObject stores body under key and returns the location COPY reads it from, like s3://bucket/key.
+	WriteObject(ctx context.Context, key string, body io.Reader) (string, error)
+}
+
+type BulkInsertOptions struct {
+	// MaxStatementBytes bounds the size of each INSERT statement. default = 100 KB
+	MaxStatementBytes int
+	// MaxRowsPerStatement bounds the rows of each INSERT statement. default = unlimited
+	MaxRowsPerStatement int
+
+	// Copy makes BulkInsert stage the rows as one CSV object and load it with COPY instead of INSERT.
+	Copy *BulkCopyOptions
+}
+
+type BulkCopyOptions struct {
+	Writer ObjectWriter
+	// Key is the key of the staged object, which is left in place after COPY.
+	Key string
+	// IAMRole is the role COPY reads the object with. default = the default IAM role of the cluster
+	IAMRole string
+	// Region is the region of the bucket if it differs from the cluster's.
+	Region string
+}
+
+// bulkNullText marks NULL in the staged CSV. A value equal to it can not be loaded and is rejected.
+const bulkNullText = `\N`
+
+// BulkInsert inserts rows into the columns of table and returns the number of rows inserted.
+// table may be schema qualified as schema.table; its parts and the columns are quoted as identifiers.
+//
+// The rows are sent in multi-row INSERT statements of at most MaxStatementBytes, each of which is
+// committed on its own, so on error the rows of the statements before it stay inserted.
+// With Copy set the rows are loaded at once with COPY.
+func BulkInsert(ctx context.Context, db *sql.DB, table string, columns []string, rows BulkRows, opts *BulkInsertOptions) (int64, error) {
+	if len(columns) == 0 {
+		return 0, errors.New("bulk insert: no columns")
+	}
+	if opts == nil {
+		opts = &BulkInsertOptions{}
+	}
+	if opts.Copy != nil {
+		return bulkCopy(ctx, db, table, columns, rows, opts.Copy)
+	}
+	maxBytes := opts.MaxStatementBytes
+	if maxBytes <= 0 {
+		maxBytes = maxBatchSQLBytes
+	}
+	prefix := fmt.Sprintf("INSERT INTO %s (%s) VALUES ", sqlquote.Table(table), sqlquote.Idents(columns))
+	// The statements are measured with the query comment the driver appends to them;
+	// with other drivers there is none.
+	var commentSize int
+	if _, cfg, err := rawClient(ctx, db); err == nil {
+		commentSize = cfg.queryCommentSize(ctx)
+	}
+	var inserted int64
+	var b strings.Builder
+	var n, index int
+	flush := func() error {
+		if n == 0 {
+			return nil
+		}
+		result, err := db.ExecContext(ctx, b.String())
+		if err != nil {
+			return fmt.Errorf("bulk insert rows %d-%d: %w", index-n+1, index, err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		inserted += affected
+		b.Reset()
+		n = 0
+		return nil
+	}
+	for {
+		row, err := rows.NextRow()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return inserted, fmt.Errorf("bulk insert row %d: %w", index+1, err)
+		}
+		tuple, err := formatTuple(row, len(columns))
+		if err != nil {
+			return inserted, fmt.Errorf("bulk insert row %d: %w", index+1, err)
+		}
+		if size := commentSize + len(prefix) + len(tuple); size > maxBytes {
+			return inserted, fmt.Errorf("bulk insert row %d: %d bytes exceed the statement limit of %d bytes", index+1, size, maxBytes)
+		}
+		if n > 0 && (commentSize+b.Len()+2+len(tuple) > maxBytes || (opts.MaxRowsPerStatement > 0 && n == opts.MaxRowsPerStatement)) {
+			if err := flush(); err != nil {
+				return inserted, err
+			}
+		}
+		if n == 0 {
+			b.WriteString(prefix)
+		} else {
+			b.WriteString(", ")
+		}
+		b.WriteString(tuple)
+		n++
+		index++
+	}
+	if err := flush(); err != nil {
+		return inserted, err
+	}
+	return inserted, nil
+}
+
+func bulkCopy(ctx context.Context, db *sql.DB, table string, columns []string, rows BulkRows, opts *BulkCopyOptions) (int64, error) {
+	if opts.Writer == nil {
+		return 0, errors.New("bulk copy: no object writer")
+	}
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeCSV(pw, rows, len(columns)))
+	}()
+	location, err := opts.Writer.WriteObject(ctx, opts.Key, pr)
+	pr.CloseWithError(err)
+	if err != nil {
+		return 0, fmt.Errorf("bulk copy: write object: %w", err)
+	}
+	n, err := redshiftdatacopy.Copy(table, location).
+		WithColumns(columns...).
+		WithIAMRole(opts.IAMRole).
+		WithFormat(redshiftdatacopy.FormatCSV).
+		WithNullAs(bulkNullText).
+		WithTimeFormat("auto").
+		WithRegion(opts.Region).
+		Exec(ctx, db)
+	if err != nil {
+		return 0, fmt.Errorf("bulk copy: %w", err)
+	}
+	return n, nil
+}
+
+func writeCSV(w io.Writer, rows BulkRows, numColumns int) error {
+	cw := csv.NewWriter(w)
+	record := make([]string, numColumns)
+	for index := 1; ; index++ {
+		row, err := rows.NextRow()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("row %d: %w", index, err)
+		}
+		if len(row) != numColumns {
+			return fmt.Errorf("row %d has %d values, want %d", index, len(row), numColumns)
+		}
+		for i, v := range row {
+			text, kind, err := bulkText(v)
+			if err != nil {
+				return fmt.Errorf("row %d: column %d: %w", index, i+1, err)
+			}
+			switch {
+			case kind == bulkNull:
+				text = bulkNullText
+			case text == bulkNullText:
+				return fmt.Errorf("row %d: column %d: value %q is the NULL marker of COPY", index, i+1, text)
+			}
+			record[i] = text
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatTuple(row []any, numColumns int) (string, error) {
+	if len(row) != numColumns {
+		return "", fmt.Errorf("row has %d values, want %d", len(row), numColumns)
+	}
+	var b strings.Builder
+	b.WriteByte('(')
+	for i, v := range row {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		text, kind, err := bulkText(v)
+		if err != nil {
+			return "", fmt.Errorf("column %d: %w", i+1, err)
+		}
+		switch kind {
+		case bulkNull:
+			b.WriteString("NULL")
+		case bulkQuoted:
+			b.WriteString(sqlquote.Literal(text))
+		default:
+			b.WriteString(text)
+		}
+	}
+	b.WriteByte(')')
+	return b.String(), nil
+}
+
+type bulkKind int
+
+const (
+	bulkNull bulkKind = iota
+	bulkBare
+	bulkQuoted
+)
+
+// bulkText returns v as text Redshift reads back as the same value,
+// and whether it is NULL, a bare literal or one that must be quoted.
+func bulkText(v any) (string, bulkKind, error) {
+	v, err := driver.DefaultParameterConverter.ConvertValue(v)
+	if err != nil {
+		return "", bulkNull, err
+	}
+	switch v := v.(type) {
+	case nil:
+		return "", bulkNull, nil
+	case int64:
+		return strconv.FormatInt(v, 10), bulkBare, nil
+	case float64:
+		switch {
+		case math.IsNaN(v):
+			return "NaN", bulkQuoted, nil
+		case math.IsInf(v, 1):
+			return "Infinity", bulkQuoted, nil
+		case math.IsInf(v, -1):
+			return "-Infinity", bulkQuoted, nil
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64), bulkBare, nil
+	case bool:
+		return strconv.FormatBool(v), bulkBare, nil
+	case time.Time:
+		// In UTC, so that TIMESTAMP columns, which drop the offset, get the same instant.
+		return v.UTC().Format("2006-01-02 15:04:05.999999-07:00"), bulkQuoted, nil
+	case []byte:
+		return string(v), bulkQuoted, nil
+	case string:
+		return v, bulkQuoted, nil
+	}
+	return "", bulkNull, fmt.Errorf("unsupported value type %T", v)
+}
@@ -0,0 +1,139 @@
+package redshiftdatasqldriver
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mashiike/redshift-data-sql-driver/redshiftdatamock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatTuple(t *testing.T) {
+	tuple, err := formatTuple([]any{
+		nil,
+		int32(-3),
+		1.5,
+		math.Inf(-1),
+		true,
+		`it's a \ test`,
+		[]byte("raw"),
+		time.Date(2024, 1, 2, 3, 4, 5, 600000000, time.UTC),
+		time.Date(2024, 1, 2, 12, 4, 5, 0, time.FixedZone("JST", 9*60*60)),
+		sql.NullString{},
+	}, 10)
+	require.NoError(t, err)
+	require.Equal(t, `(NULL, -3, 1.5, '-Infinity', true, 'it''s a \\ test', 'raw', '2024-01-02 03:04:05.6+00:00', '2024-01-02 03:04:05+00:00', NULL)`, tuple)
+
+	_, err = formatTuple([]any{1}, 2)
+	require.EqualError(t, err, "row has 1 values, want 2")
+	_, err = formatTuple([]any{struct{}{}}, 1)
+	require.Error(t, err)
+}
+
+func TestBulkInsert(t *testing.T) {
+	client := redshiftdatamock.New()
+	client.ExpectExecute(`INSERT INTO "public"."users" ("id", "name") VALUES (1, 'hoge'), (2, 'fuga')`).WillReturnResult(2)
+	client.ExpectExecute(`INSERT INTO "public"."users" ("id", "name") VALUES (3, NULL)`).WillReturnResult(1)
+	db := openTestDB(t, client)
+	restore := requireNoErrorLog(t)
+	defer restore()
+
+	n, err := BulkInsert(context.Background(), db, "public.users", []string{"id", "name"}, SliceRows([][]any{
+		{1, "hoge"},
+		{2, "fuga"},
+		{3, nil},
+	}), &BulkInsertOptions{MaxRowsPerStatement: 2})
+	require.NoError(t, err)
+	require.EqualValues(t, 3, n)
+	require.NoError(t, client.ExpectationsWereMet())
+}
+
+func TestBulkInsertMaxStatementBytes(t *testing.T) {
+	client := redshiftdatamock.New()
+	client.ExpectExecuteMatch(`^INSERT INTO "t" \("v"\) VALUES \('x+'\)$`).WillReturnResult(1).Times(3)
+	db := openTestDB(t, client)
+	restore := requireNoErrorLog(t)
+	defer restore()
+
+	long := strings.Repeat("x", 40)
+	n, err := BulkInsert(context.Background(), db, "t", []string{"v"}, SliceRows([][]any{{long}, {long}, {long}}), &BulkInsertOptions{MaxStatementBytes: 80})
+	require.NoError(t, err)
+	require.EqualValues(t, 3, n)
+	require.NoError(t, client.ExpectationsWereMet())
+
+	_, err = BulkInsert(context.Background(), db, "t", []string{"v"}, SliceRows([][]any{{strings.Repeat("x", 60)}}), &BulkInsertOptions{MaxStatementBytes: 80})
+	require.EqualError(t, err, "bulk insert row 1: 93 bytes exceed the statement limit of 80 bytes")
+	require.Equal(t, 3, client.CallCount(redshiftdatamock.OperationExecuteStatement))
+
+	// The query comment the driver appends counts toward the limit.
+	ctx := WithQueryTags(context.Background(), map[string]string{"app": "api"})
+	_, err = BulkInsert(ctx, db, "t", []string{"v"}, SliceRows([][]any{{long}}), &BulkInsertOptions{MaxStatementBytes: 80})
+	require.EqualError(t, err, "bulk insert row 1: 87 bytes exceed the statement limit of 80 bytes")
+	require.Equal(t, 3, client.CallCount(redshiftdatamock.OperationExecuteStatement))
+}
+
+type memoryObjectWriter struct {
+	key  string
+	body string
+	err  error
+}
+
+func (w *memoryObjectWriter) WriteObject(ctx context.Context, key string, body io.Reader) (string, error) {
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+	if w.err != nil {
+		return "", w.err
+	}
+	w.key = key
+	w.body = string(b)
+	return "s3://bucket/" + key, nil
+}
+
+func TestBulkInsertCopy(t *testing.T) {
+	client := redshiftdatamock.New()
+	client.ExpectExecute(`COPY "users" ("id", "name") FROM 's3://bucket/load/users.csv' FORMAT AS CSV IAM_ROLE 'arn:aws:iam::123456789012:role/copy' NULL AS '\\N' TIMEFORMAT 'auto' REGION 'us-west-2'`).WillReturnResult(3)
+	db := openTestDB(t, client)
+	restore := requireNoErrorLog(t)
+	defer restore()
+
+	writer := &memoryObjectWriter{}
+	n, err := BulkInsert(context.Background(), db, "users", []string{"id", "name"}, SliceRows([][]any{
+		{1, "hoge"},
+		{2, "a,\"b\""},
+		{3, nil},
+	}), &BulkInsertOptions{Copy: &BulkCopyOptions{
+		Writer:  writer,
+		Key:     "load/users.csv",
+		IAMRole: "arn:aws:iam::123456789012:role/copy",
+		Region:  "us-west-2",
+	}})
+	require.NoError(t, err)
+	require.EqualValues(t, 3, n)
+	require.Equal(t, "load/users.csv", writer.key)
+	require.Equal(t, "1,hoge\n2,\"a,\"\"b\"\"\"\n3,\\N\n", writer.body)
+	require.NoError(t, client.ExpectationsWereMet())
+
+	_, err = BulkInsert(context.Background(), db, "users", []string{"id", "name"}, SliceRows([][]any{{1}}), &BulkInsertOptions{Copy: &BulkCopyOptions{
+		Writer: &memoryObjectWriter{},
+	}})
+	require.EqualError(t, err, "bulk copy: write object: row 1 has 1 values, want 2")
+
+	_, err = BulkInsert(context.Background(), db, "users", []string{"id", "name"}, SliceRows([][]any{{1, `\N`}}), &BulkInsertOptions{Copy: &BulkCopyOptions{
+		Writer: &memoryObjectWriter{},
+	}})
+	require.EqualError(t, err, `bulk copy: write object: row 1: column 2: value "\\N" is the NULL marker of COPY`)
+
+	writeErr := errors.New("access denied")
+	_, err = BulkInsert(context.Background(), db, "users", []string{"id"}, SliceRows([][]any{{1}}), &BulkInsertOptions{Copy: &BulkCopyOptions{
+		Writer: &memoryObjectWriter{err: writeErr},
+	}})
+	require.ErrorIs(t, err, writeErr)
+}
@@ -0,0 +1,291 @@
+package redshiftdatasqldriver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
+)
+
+// RedshiftDataCatalogClient is the part of the Data API used by Catalog.
+// The client made by DefaultRedshiftDataClientConstructor satisfies it.
+type RedshiftDataCatalogClient interface {
+	redshiftdata.ListDatabasesAPIClient
+	redshiftdata.ListSchemasAPIClient
+	redshiftdata.ListTablesAPIClient
+	redshiftdata.DescribeTableAPIClient
+}
+
+// Catalog lists the databases, schemas and tables of the cluster or workgroup of a DSN
+// with the Data API metadata operations, without running queries.
+type Catalog struct {
+	client RedshiftDataClient
+	cfg    *RedshiftDataConfig
+}
+
+// CatalogOptions narrow a listing. Patterns use LIKE syntax, where % matches any characters and _ one character.
+type CatalogOptions struct {
+	// Database is the database to list. default = the database of the DSN
+	Database        string
+	DatabasePattern string
+	SchemaPattern   string
+	TablePattern    string
+	// PageSize is the number of results per API call. default = the Data API's
+	PageSize int32
+	// Limit stops the listing after this many results. default = unlimited
+	Limit int
+}
+
+type Table struct {
+	Schema string
+	Name   string
+	// Type is TABLE, VIEW, SYSTEM TABLE, GLOBAL TEMPORARY, LOCAL TEMPORARY, ALIAS or SYNONYM.
+	Type string
+}
+
+type TableDescription struct {
+	Schema  string
+	Name    string
+	Columns []Column
+}
+
+type Column struct {
+	Name string
+	// TypeName is the Redshift type, such as varchar, int4 or timestamptz.
+	TypeName  string
+	Length    int32
+	Precision int32
+	Scale     int32
+	Nullable  bool
+	// Default is the default expression, or nil if the column has none.
+	Default         *string
+	IsCaseSensitive bool
+	IsSigned        bool
+	IsCurrency      bool
+}
+
+// NewCatalog returns the Catalog of the DSN db was opened with.
+func NewCatalog(ctx context.Context, db *sql.DB) (*Catalog, error) {
+	client, cfg, err := rawClient(ctx, db)
+	if err != nil {
+		return nil, fmt.Errorf("catalog: %w", err)
+	}
+	return &Catalog{client: client, cfg: cfg}, nil
+}
+
+// NewConnectorCatalog returns the Catalog of a connector made by NewConnector.
+func NewConnectorCatalog(ctx context.Context, connector driver.Connector) (*Catalog, error) {
+	client, cfg, err := connectorClient(ctx, connector)
+	if err != nil {
+		return nil, fmt.Errorf("catalog: %w", err)
+	}
+	return &Catalog{client: client, cfg: cfg}, nil
+}
+
+func (c *Catalog) catalogClient() (RedshiftDataCatalogClient, error) {
+	client, ok := c.client.(RedshiftDataCatalogClient)
+	if !ok {
+		return nil, fmt.Errorf("catalog: metadata operations %w", ErrNotSupported)
+	}
+	return client, nil
+}
+
+// databases returns the database to list and the database to connect to, which is set only if they differ.
+func (c *Catalog) databases(ctx context.Context, opts *CatalogOptions) (*string, *string) {
+	connected := c.cfg.database(ctx)
+	if opts.Database == "" || opts.Database == coalesce(connected) {
+		return connected, nil
+	}
+	return &opts.Database, connected
+}
+
+// Databases lists the databases matching DatabasePattern.
+func (c *Catalog) Databases(ctx context.Context, opts *CatalogOptions) ([]string, error) {
+	client, err := c.catalogClient()
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &CatalogOptions{}
+	}
+	match := likeMatcher(opts.DatabasePattern)
+	p := redshiftdata.NewListDatabasesPaginator(client, &redshiftdata.ListDatabasesInput{
+		ClusterIdentifier: c.cfg.ClusterIdentifier,
+		Database:          c.cfg.database(ctx),
+		DbUser:            c.cfg.dbUser(ctx),
+		WorkgroupName:     c.cfg.WorkgroupName,
+		SecretArn:         c.cfg.SecretsARN,
+		MaxResults:        opts.PageSize,
+	})
+	var databases []string
+	for p.HasMorePages() && !limitReached(len(databases), opts.Limit) {
+		output, err := p.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list databases: %w", err)
+		}
+		for _, name := range output.Databases {
+			if match(name) {
+				databases = append(databases, name)
+			}
+		}
+	}
+	return truncate(databases, opts.Limit), nil
+}
+
+// Schemas lists the schemas of Database matching SchemaPattern.
+func (c *Catalog) Schemas(ctx context.Context, opts *CatalogOptions) ([]string, error) {
+	client, err := c.catalogClient()
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &CatalogOptions{}
+	}
+	database, connected := c.databases(ctx, opts)
+	p := redshiftdata.NewListSchemasPaginator(client, &redshiftdata.ListSchemasInput{
+		ClusterIdentifier: c.cfg.ClusterIdentifier,
+		Database:          database,
+		ConnectedDatabase: connected,
+		DbUser:            c.cfg.dbUser(ctx),
+		WorkgroupName:     c.cfg.WorkgroupName,
+		SecretArn:         c.cfg.SecretsARN,
+		SchemaPattern:     nullif(opts.SchemaPattern),
+		MaxResults:        opts.PageSize,
+	})
+	var schemas []string
+	for p.HasMorePages() && !limitReached(len(schemas), opts.Limit) {
+		output, err := p.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list schemas: %w", err)
+		}
+		schemas = append(schemas, output.Schemas...)
+	}
+	return truncate(schemas, opts.Limit), nil
+}
+
+// Tables lists the tables and views of Database matching SchemaPattern and TablePattern.
+func (c *Catalog) Tables(ctx context.Context, opts *CatalogOptions) ([]Table, error) {
+	client, err := c.catalogClient()
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &CatalogOptions{}
+	}
+	database, connected := c.databases(ctx, opts)
+	p := redshiftdata.NewListTablesPaginator(client, &redshiftdata.ListTablesInput{
+		ClusterIdentifier: c.cfg.ClusterIdentifier,
+		Database:          database,
+		ConnectedDatabase: connected,
+		DbUser:            c.cfg.dbUser(ctx),
+		WorkgroupName:     c.cfg.WorkgroupName,
+		SecretArn:         c.cfg.SecretsARN,
+		SchemaPattern:     nullif(opts.SchemaPattern),
+		TablePattern:      nullif(opts.TablePattern),
+		MaxResults:        opts.PageSize,
+	})
+	var tables []Table
+	for p.HasMorePages() && !limitReached(len(tables), opts.Limit) {
+		output, err := p.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list tables: %w", err)
+		}
+		for _, t := range output.Tables {
+			tables = append(tables, Table{
+				Schema: coalesce(t.Schema),
+				Name:   coalesce(t.Name),
+				Type:   coalesce(t.Type),
+			})
+		}
+	}
+	return truncate(tables, opts.Limit), nil
+}
+
+// DescribeTable returns the columns of schema.table in Database. Patterns and Limit are ignored.
+func (c *Catalog) DescribeTable(ctx context.Context, schema, table string, opts *CatalogOptions) (*TableDescription, error) {
+	client, err := c.catalogClient()
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &CatalogOptions{}
+	}
+	database, connected := c.databases(ctx, opts)
+	p := redshiftdata.NewDescribeTablePaginator(client, &redshiftdata.DescribeTableInput{
+		ClusterIdentifier: c.cfg.ClusterIdentifier,
+		Database:          database,
+		ConnectedDatabase: connected,
+		DbUser:            c.cfg.dbUser(ctx),
+		WorkgroupName:     c.cfg.WorkgroupName,
+		SecretArn:         c.cfg.SecretsARN,
+		Schema:            nullif(schema),
+		Table:             nullif(table),
+		MaxResults:        opts.PageSize,
+	})
+	desc := &TableDescription{
+		Schema: schema,
+		Name:   table,
+	}
+	for p.HasMorePages() {
+		output, err := p.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("describe table: %w", err)
+		}
+		for _, col := range output.ColumnList {
+			desc.Columns = append(desc.Columns, Column{
+				Name:            coalesce(col.Name),
+				TypeName:        coalesce(col.TypeName),
+				Length:          col.Length,
+				Precision:       col.Precision,
+				Scale:           col.Scale,
+				Nullable:        col.Nullable != 0,
+				Default:         col.ColumnDefault,
+				IsCaseSensitive: col.IsCaseSensitive,
+				IsSigned:        col.IsSigned,
+				IsCurrency:      col.IsCurrency,
+			})
+		}
+	}
+	return desc, nil
+}
+
+func limitReached(n, limit int) bool {
+	return limit > 0 && n >= limit
+}
+
+func truncate[T any](items []T, limit int) []T {
+	if limitReached(len(items), limit) {
+		return items[:limit]
+	}
+	return items
+}
+
+// likeMatcher returns a function reporting whether a name matches the LIKE pattern.
+// An empty pattern matches every name.
+func likeMatcher(pattern string) func(string) bool {
+	if pattern == "" {
+		return func(string) bool { return true }
+	}
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '%':
+			b.WriteString(".*")
+		case c == '_':
+			b.WriteString(".")
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	b.WriteString("$")
+	re := regexp.MustCompile("(?s)" + b.String())
+	return re.MatchString
+}
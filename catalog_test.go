@@ -0,0 +1,129 @@
+package redshiftdatasqldriver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
+	"github.com/mashiike/redshift-data-sql-driver/redshiftdatamock"
+	"github.com/stretchr/testify/require"
+)
+
+// catalogRecordingClient records the ListSchemas inputs, to check which database is connected to.
+type catalogRecordingClient struct {
+	*redshiftdatamock.Client
+	listSchemas []*redshiftdata.ListSchemasInput
+}
+
+func (c *catalogRecordingClient) ListSchemas(ctx context.Context, params *redshiftdata.ListSchemasInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ListSchemasOutput, error) {
+	c.listSchemas = append(c.listSchemas, params)
+	return c.Client.ListSchemas(ctx, params, optFns...)
+}
+
+func newCatalogTestClient() *catalogRecordingClient {
+	client := redshiftdatamock.New().
+		AddTable("dev", "public", "users", "TABLE",
+			types.ColumnMetadata{Name: aws.String("id"), TypeName: aws.String("int8"), Precision: 19, IsSigned: true},
+			types.ColumnMetadata{Name: aws.String("name"), TypeName: aws.String("varchar"), Length: 256, Nullable: 1, IsCaseSensitive: true, ColumnDefault: aws.String("'anonymous'::character varying")},
+		).
+		AddTable("dev", "public", "active_users", "VIEW").
+		AddTable("dev", "staging", "users", "TABLE").
+		AddDatabase("dev_archive").
+		AddTable("prod", "public", "users", "TABLE").
+		AddTable("prod", "publish", "reports", "TABLE").
+		AddTable("prod", "private", "secrets", "TABLE").
+		AddDatabase("dev2")
+	return &catalogRecordingClient{Client: client}
+}
+
+func TestCatalog(t *testing.T) {
+	client := newCatalogTestClient()
+	db := openTestDB(t, client)
+	restore := requireNoErrorLog(t)
+	defer restore()
+	ctx := context.Background()
+
+	catalog, err := NewCatalog(ctx, db)
+	require.NoError(t, err)
+
+	databases, err := catalog.Databases(ctx, &CatalogOptions{DatabasePattern: "dev_"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"dev2"}, databases)
+	databases, err = catalog.Databases(ctx, &CatalogOptions{Limit: 1})
+	require.NoError(t, err)
+	require.Equal(t, []string{"dev"}, databases)
+
+	schemas, err := catalog.Schemas(ctx, &CatalogOptions{Database: "prod", SchemaPattern: "pub%"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"public", "publish"}, schemas)
+	require.Equal(t, "prod", aws.ToString(client.listSchemas[0].Database))
+	require.Equal(t, "dev", aws.ToString(client.listSchemas[0].ConnectedDatabase))
+	schemas, err = catalog.Schemas(ctx, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"public", "staging"}, schemas)
+	require.Equal(t, "dev", aws.ToString(client.listSchemas[1].Database))
+	require.Nil(t, client.listSchemas[1].ConnectedDatabase)
+
+	tables, err := catalog.Tables(ctx, &CatalogOptions{SchemaPattern: "public", PageSize: 1})
+	require.NoError(t, err)
+	require.Equal(t, []Table{
+		{Schema: "public", Name: "users", Type: "TABLE"},
+		{Schema: "public", Name: "active_users", Type: "VIEW"},
+	}, tables)
+
+	desc, err := catalog.DescribeTable(ctx, "public", "users", nil)
+	require.NoError(t, err)
+	require.Equal(t, &TableDescription{
+		Schema: "public",
+		Name:   "users",
+		Columns: []Column{
+			{Name: "id", TypeName: "int8", Precision: 19, IsSigned: true},
+			{Name: "name", TypeName: "varchar", Length: 256, Nullable: true, IsCaseSensitive: true, Default: aws.String("'anonymous'::character varying")},
+		},
+	}, desc)
+}
+
+func TestConnectorCatalog(t *testing.T) {
+	client := newCatalogTestClient()
+	recorder := &testMetricsRecorder{}
+	cfg := (&RedshiftDataConfig{
+		WorkgroupName:   aws.String("default"),
+		Database:        aws.String("dev"),
+		MetricsRecorder: recorder,
+	}).WithRedshiftDataClientConstructor(func(ctx context.Context, cfg *RedshiftDataConfig) (RedshiftDataClient, error) {
+		return client, nil
+	})
+	catalog, err := NewConnectorCatalog(context.Background(), NewConnector(cfg))
+	require.NoError(t, err)
+	databases, err := catalog.Databases(context.Background(), nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"dev", "dev_archive", "prod", "dev2"}, databases)
+	require.Equal(t, 1, recorder.apiCalls["ListDatabases"])
+	require.Equal(t, 1, client.CallCount(redshiftdatamock.OperationListDatabases))
+}
+
+func TestCatalogNotSupported(t *testing.T) {
+	cfg := (&RedshiftDataConfig{
+		WorkgroupName: aws.String("default"),
+		Database:      aws.String("dev"),
+	}).WithRedshiftDataClientConstructor(func(ctx context.Context, cfg *RedshiftDataConfig) (RedshiftDataClient, error) {
+		return struct{ RedshiftDataClient }{redshiftdatamock.New()}, nil
+	})
+	catalog, err := NewConnectorCatalog(context.Background(), NewConnector(cfg))
+	require.NoError(t, err)
+	_, err = catalog.Tables(context.Background(), nil)
+	require.ErrorIs(t, err, ErrNotSupported)
+}
+
+func TestLikeMatcher(t *testing.T) {
+	match := likeMatcher(`us_r\_%`)
+	require.True(t, match("user_events"))
+	require.True(t, match("usar_"))
+	require.False(t, match("users"))
+	require.False(t, match("xuser_"))
+	require.True(t, likeMatcher("")("anything"))
+	require.True(t, likeMatcher("a.b")("a.b"))
+	require.False(t, likeMatcher("a.b")("axb"))
+}
@@ -2,6 +2,9 @@ package redshiftdatasqldriver
 
 import (
 	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
 
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
@@ -18,6 +21,9 @@ type RedshiftDataClient interface {
 var RedshiftDataClientConstructor func(ctx context.Context, cfg *RedshiftDataConfig) (RedshiftDataClient, error)
 
 func newRedshiftDataClient(ctx context.Context, cfg *RedshiftDataConfig) (RedshiftDataClient, error) {
+	if cfg.RedshiftDataClientConstructor != nil {
+		return cfg.RedshiftDataClientConstructor(ctx, cfg)
+	}
 	if RedshiftDataClientConstructor != nil {
 		return RedshiftDataClientConstructor(ctx, cfg)
 	}
@@ -32,3 +38,36 @@ func DefaultRedshiftDataClientConstructor(ctx context.Context, cfg *RedshiftData
 	client := redshiftdata.NewFromConfig(awsCfg, cfg.RedshiftDataOptFns...)
 	return client, nil
 }
+
+// rawClient returns the client and config of a connection of db, for APIs outside database/sql.
+func rawClient(ctx context.Context, db *sql.DB) (RedshiftDataClient, *RedshiftDataConfig, error) {
+	c, err := db.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer c.Close()
+	var client RedshiftDataClient
+	var cfg *RedshiftDataConfig
+	err = c.Raw(func(driverConn any) error {
+		conn, ok := driverConn.(*redshiftDataConn)
+		if !ok {
+			return fmt.Errorf("%T: %w", driverConn, ErrNotSupported)
+		}
+		client, cfg = conn.client, conn.cfg
+		return nil
+	})
+	return client, cfg, err
+}
+
+// connectorClient returns a new client for a connector made by NewConnector, and its config.
+func connectorClient(ctx context.Context, connector driver.Connector) (RedshiftDataClient, *RedshiftDataConfig, error) {
+	c, ok := connector.(*redshiftDataConnector)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T: %w", connector, ErrNotSupported)
+	}
+	client, err := newRedshiftDataClient(ctx, c.cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return newMetricsClient(client, c.cfg.MetricsRecorder), c.cfg, nil
+}
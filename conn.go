@@ -4,310 +4,543 @@ import (
 	"context"
 	"database/sql"
 	"database/sql/driver"
+	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
 	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+const pingQuery = "SELECT 1"
+
+// closeCancelTimeout bounds the CancelStatement calls made by Close.
+const closeCancelTimeout = 10 * time.Second
+
 type redshiftDataConn struct {
 	client   RedshiftDataClient
 	cfg      *RedshiftDataConfig
 	aliveCh  chan struct{}
 	isClosed bool
 
-	inTx          bool
-	txOpts        driver.TxOptions
+	mu       sync.Mutex
+	inflight map[string]struct{}
+	limiter  *StatementLimiter
+
+	// tx is the transaction in progress, guarded by mu.
+	tx *redshiftDataTxState
+}
+
+// redshiftDataTxState holds the statements queued in a transaction until commit.
+type redshiftDataTxState struct {
+	opts          driver.TxOptions
 	sqls          []string
 	delayedResult []*redshiftDataDelayedResult
 }
 
 func newConn(client RedshiftDataClient, cfg *RedshiftDataConfig) *redshiftDataConn {
-	return &redshiftDataConn{
+	conn := &redshiftDataConn{
 		client:  client,
 		cfg:     cfg,
 		aliveCh: make(chan struct{}),
 	}
+	conns.add(conn)
+	return conn
 }
 
 func (conn *redshiftDataConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
-	return nil, fmt.Errorf("prepared statment %w", ErrNotSupported)
+	return newStmt(conn, query), nil
 }
 
 func (conn *redshiftDataConn) Prepare(query string) (driver.Stmt, error) {
 	return conn.PrepareContext(context.Background(), query)
 }
 
+// Close stops the statements waited on this connection and cancels the unfinished ones on Redshift.
 func (conn *redshiftDataConn) Close() error {
+	conn.mu.Lock()
 	if conn.isClosed {
+		conn.mu.Unlock()
 		return nil
 	}
 	conn.isClosed = true
 	close(conn.aliveCh)
+	ids := make([]string, 0, len(conn.inflight))
+	for id := range conn.inflight {
+		ids = append(ids, id)
+	}
+	conn.inflight = nil
+	conn.mu.Unlock()
+	conns.remove(conn)
+	conn.cancelInflight(ids)
 	return nil
 }
 
-func (conn *redshiftDataConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
-	if conn.inTx {
-		return nil, ErrInTx
+// trackStatement records id as in flight until the returned func is called.
+// It fails with ErrConnClosed once Close has cancelled the statements in flight.
+func (conn *redshiftDataConn) trackStatement(id *string) (func(), error) {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	if conn.isClosed {
+		return nil, ErrConnClosed
+	}
+	if conn.inflight == nil {
+		conn.inflight = make(map[string]struct{})
 	}
+	conn.inflight[coalesce(id)] = struct{}{}
+	return func() {
+		conn.mu.Lock()
+		defer conn.mu.Unlock()
+		delete(conn.inflight, coalesce(id))
+	}, nil
+}
+
+func (conn *redshiftDataConn) cancelInflight(ids []string) {
+	if len(ids) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), closeCancelTimeout)
+	defer cancel()
+	for _, id := range ids {
+		conn.cfg.logDebug(ctx, "cancel in-flight statement on close", slog.String("statement_id", id))
+		output, err := conn.client.CancelStatement(ctx, &redshiftdata.CancelStatementInput{
+			Id: aws.String(id),
+		})
+		if err != nil {
+			conn.cfg.logError(ctx, "failed cancel in-flight statement", slog.String("statement_id", id), slog.Any("error", err))
+			continue
+		}
+		if !*output.Status {
+			conn.cfg.logDebug(ctx, "cancel statement status is false", slog.String("statement_id", id))
+		}
+	}
+}
+
+// Ping runs SELECT 1, so that a wrong workgroup, cluster, database or credentials are reported.
+func (conn *redshiftDataConn) Ping(ctx context.Context) error {
+	if !conn.IsValid() {
+		return driver.ErrBadConn
+	}
+	ctx, span := conn.cfg.startSpan(ctx, "Ping", pingQuery)
+	_, output, err := conn.executeStatement(ctx, &redshiftdata.ExecuteStatementInput{
+		Sql: aws.String(pingQuery),
+	})
+	endSpan(span, output, err)
+	if err != nil {
+		return fmt.Errorf("ping: %w", err)
+	}
+	return nil
+}
+
+func (conn *redshiftDataConn) IsValid() bool {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	return !conn.isClosed
+}
+
+func (conn *redshiftDataConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
 	if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) {
 		return nil, fmt.Errorf("transaction isolation level change: %w", ErrNotSupported)
 	}
-	conn.inTx = true
-	conn.txOpts = opts
-	cleanup := func() error {
-		conn.inTx = false
-		conn.sqls = nil
-		conn.delayedResult = nil
-		return nil
+	state := &redshiftDataTxState{
+		opts: opts,
 	}
+	conn.mu.Lock()
+	if conn.tx != nil {
+		conn.mu.Unlock()
+		return nil, ErrInTx
+	}
+	conn.tx = state
+	conn.mu.Unlock()
 	tx := &redshiftDataTx{
+		cfg: conn.cfg,
 		onRollback: func() error {
-			if !conn.inTx {
-				return ErrNotInTx
-			}
-			return cleanup()
+			return conn.endTx(state)
 		},
 		onCommit: func() error {
-			if !conn.inTx {
-				return ErrNotInTx
+			if err := conn.endTx(state); err != nil {
+				return err
 			}
-			if len(conn.sqls) == 0 {
-				return cleanup()
+			if len(state.sqls) == 0 {
+				return nil
 			}
-			if len(conn.sqls) != len(conn.delayedResult) {
-				panic(fmt.Sprintf("sqls and delayedResult length is not match: sqls=%d delayedResult=%d", len(conn.sqls), len(conn.delayedResult)))
+			size := conn.cfg.taggedSize(ctx)
+			if i := oversizedStatement(state.sqls, size); i >= 0 {
+				return fmt.Errorf("commit statement %d of %d bytes: %w", i+1, size(state.sqls[i]), ErrTxTooLarge)
 			}
-			if len(conn.sqls) == 1 {
-				result, err := conn.ExecContext(ctx, conn.sqls[0], []driver.NamedValue{})
-				if err != nil {
-					return err
-				}
-				if conn.delayedResult[0] != nil {
-					conn.delayedResult[0].Result = result
+			var results []driver.Result
+			var err error
+			if exceedsBatchLimits(state.sqls, size) {
+				if !conn.cfg.SessionTransactions {
+					return fmt.Errorf("commit %d statements: %w", len(state.sqls), ErrTxTooLarge)
 				}
-				return nil
+				results, err = conn.execInSession(ctx, HookKindCommit, state.sqls)
+			} else {
+				results, err = conn.execBatch(ctx, HookKindCommit, state.sqls)
 			}
-			input := &redshiftdata.BatchExecuteStatementInput{
-				Sqls: append(make([]string, 0, len(conn.sqls)), conn.sqls...),
-			}
-			_, desc, err := conn.batchExecuteStatement(ctx, input)
 			if err != nil {
 				return err
 			}
-			for i := range input.Sqls {
-				if i >= len(desc.SubStatements) {
-					return fmt.Errorf("sub statement not found: %d", i)
-				}
-				if conn.delayedResult[i] != nil {
-					conn.delayedResult[i].Result = newResultWithSubStatementData(desc.SubStatements[i])
-				}
+			for i, result := range results {
+				state.delayedResult[i].set(result)
 			}
-			return cleanup()
+			return nil
 		},
 	}
 
 	return tx, nil
 }
 
+// execBatch runs sqls in one BatchExecuteStatement, or one ExecuteStatement if there is a single statement,
+// and returns the result of each statement.
+func (conn *redshiftDataConn) execBatch(ctx context.Context, kind HookKind, sqls []string) ([]driver.Result, error) {
+	stmt := &HookStatement{
+		Kind: kind,
+		Sqls: append(make([]string, 0, len(sqls)), sqls...),
+	}
+	ctx, err := conn.cfg.beforeExecute(ctx, stmt)
+	if err != nil {
+		return nil, err
+	}
+	if len(stmt.Sqls) != len(sqls) {
+		return nil, conn.cfg.afterExecute(ctx, stmt, nil, errHookChangedStatements)
+	}
+	if len(stmt.Sqls) == 1 {
+		params := &redshiftdata.ExecuteStatementInput{
+			Sql: aws.String(stmt.Sqls[0]),
+		}
+		ctx, span := conn.cfg.startSpan(ctx, "ExecuteStatement", stmt.Sqls[0])
+		_, output, err := conn.executeStatement(ctx, params)
+		endSpan(span, output, err)
+		if err = conn.cfg.afterExecute(ctx, stmt, output, err); err != nil {
+			return nil, err
+		}
+		return []driver.Result{newResult(conn.cfg, output)}, nil
+	}
+	input := &redshiftdata.BatchExecuteStatementInput{
+		Sqls: append(make([]string, 0, len(stmt.Sqls)), stmt.Sqls...),
+	}
+	ctx, span := conn.cfg.startSpan(ctx, "BatchExecuteStatement", strings.Join(input.Sqls, ";\n"))
+	_, desc, err := conn.batchExecuteStatement(ctx, input)
+	endSpan(span, desc, err)
+	if err = conn.cfg.afterExecute(ctx, stmt, desc, err); err != nil {
+		return nil, err
+	}
+	results := make([]driver.Result, len(input.Sqls))
+	for i := range input.Sqls {
+		if i >= len(desc.SubStatements) {
+			return nil, fmt.Errorf("sub statement not found: %d", i)
+		}
+		results[i] = newResultWithSubStatementData(conn.cfg, desc.SubStatements[i])
+	}
+	return results, nil
+}
+
+// endTx ends the transaction state if it is still the one in progress,
+// so that a commit or rollback runs once and the connection can begin a new transaction.
+func (conn *redshiftDataConn) endTx(state *redshiftDataTxState) error {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	if conn.tx != state {
+		return ErrNotInTx
+	}
+	conn.tx = nil
+	return nil
+}
+
+// inTx reports whether a transaction is in progress.
+func (conn *redshiftDataConn) inTx() bool {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	return conn.tx != nil
+}
+
 func (conn *redshiftDataConn) Begin() (driver.Tx, error) {
 	return conn.BeginTx(context.Background(), driver.TxOptions{})
 }
 
 func (conn *redshiftDataConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
-	if conn.inTx {
+	return conn.queryContext(ctx, parseQuery(query), args)
+}
+
+func (conn *redshiftDataConn) queryContext(ctx context.Context, query *parsedQuery, args []driver.NamedValue) (driver.Rows, error) {
+	if conn.inTx() {
 		return nil, fmt.Errorf("query in transaction: %w", ErrNotSupported)
 	}
+	rewritten, parameters, err := query.bind(args)
+	if err != nil {
+		return nil, err
+	}
 
+	stmt := &HookStatement{
+		Kind:       HookKindQuery,
+		SQL:        rewritten,
+		Parameters: parameters,
+	}
+	ctx, err = conn.cfg.beforeExecute(ctx, stmt)
+	if err != nil {
+		return nil, err
+	}
 	params := &redshiftdata.ExecuteStatementInput{
-		Sql:        nullif(rewriteQuery(query, len(args))),
-		Parameters: convertArgsToParameters(args),
+		Sql:        nullif(stmt.SQL),
+		Parameters: stmt.Parameters,
 	}
+	ctx, span := conn.cfg.startSpan(ctx, "ExecuteStatement", coalesce(params.Sql))
 	p, output, err := conn.executeStatement(ctx, params)
-	if err != nil {
+	if err != nil || p == nil {
+		endSpan(span, output, err)
+	}
+	if err = conn.cfg.afterExecute(ctx, stmt, output, err); err != nil {
+		if p != nil {
+			endSpan(span, output, err)
+		}
 		return nil, err
 	}
-	rows := newRows(coalesce(output.Id), p)
+	rows := newRows(conn.cfg, coalesce(output.Id), p)
+	rows.ctx = ctx
+	rows.stmt = stmt
+	if p != nil {
+		setSpanResult(span, output)
+		rows.span = span
+	}
 	return rows, nil
 }
 
 func (conn *redshiftDataConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
-	if conn.inTx {
+	return conn.execContext(ctx, parseQuery(query), args)
+}
+
+// execContext runs query, or queues it as is in a transaction, where args are not allowed.
+// Outside a transaction a query of several statements without args is run as a script.
+func (conn *redshiftDataConn) execContext(ctx context.Context, query *parsedQuery, args []driver.NamedValue) (driver.Result, error) {
+	conn.mu.Lock()
+	if tx := conn.tx; tx != nil {
+		defer conn.mu.Unlock()
 		if len(args) > 0 {
 			return nil, fmt.Errorf("exec with args in transaction: %w", ErrNotSupported)
 		}
-		if conn.txOpts.ReadOnly {
+		if tx.opts.ReadOnly {
 			return nil, fmt.Errorf("exec in read only transaction: %w", ErrNotSupported)
 		}
-		conn.sqls = append(conn.sqls, query)
-		result := &redshiftDataDelayedResult{}
-		conn.delayedResult = append(conn.delayedResult, result)
-		debugLogger.Printf("delayedResult[%d] creaed for %q", len(conn.delayedResult)-1, query)
+		tx.sqls = append(tx.sqls, query.query)
+		result := &redshiftDataDelayedResult{cfg: conn.cfg}
+		tx.delayedResult = append(tx.delayedResult, result)
+		conn.cfg.logDebug(ctx, "delayed result created", append([]slog.Attr{slog.Int("index", len(tx.delayedResult)-1)}, conn.cfg.sqlLogAttrs(query.query)...)...)
 		return result, nil
 	}
-
-	params := &redshiftdata.ExecuteStatementInput{
-		Sql:        nullif(rewriteQuery(query, len(args))),
-		Parameters: convertArgsToParameters(args),
+	conn.mu.Unlock()
+	if len(args) == 0 {
+		if stmts := splitStatements(query.query); len(stmts) > 1 {
+			return conn.execScript(ctx, stmts)
+		}
 	}
-	_, output, err := conn.executeStatement(ctx, params)
+	rewritten, parameters, err := query.bind(args)
 	if err != nil {
 		return nil, err
 	}
-	return newResult(output), nil
-}
 
-func rewriteQuery(query string, paramsCount int) string {
-	if paramsCount == 0 {
-		return query
-	}
-	runes := make([]rune, 0, len(query))
-	stack := make([]rune, 0)
-	var exclamationCount int
-	for _, r := range query {
-		if len(stack) > 0 {
-			if r == stack[len(stack)-1] {
-				stack = stack[:len(stack)-1]
-				runes = append(runes, r)
-				continue
-			}
-		} else {
-			switch r {
-			case '?':
-				exclamationCount++
-				runes = append(runes, []rune(fmt.Sprintf(":%d", exclamationCount))...)
-				continue
-			case '$':
-				runes = append(runes, ':')
-				continue
-			}
-		}
-		switch r {
-		case '"', '\'':
-			stack = append(stack, r)
-		}
-		runes = append(runes, r)
+	stmt := &HookStatement{
+		Kind:       HookKindExec,
+		SQL:        rewritten,
+		Parameters: parameters,
 	}
-	return string(runes)
-}
-
-func convertArgsToParameters(args []driver.NamedValue) []types.SqlParameter {
-	if len(args) == 0 {
-		return nil
+	ctx, err = conn.cfg.beforeExecute(ctx, stmt)
+	if err != nil {
+		return nil, err
 	}
-	params := make([]types.SqlParameter, 0, len(args))
-	for _, arg := range args {
-		params = append(params, types.SqlParameter{
-			Name:  aws.String(coalesce(nullif(arg.Name), aws.String(fmt.Sprintf("%d", arg.Ordinal)))),
-			Value: aws.String(fmt.Sprintf("%v", arg.Value)),
-		})
+	params := &redshiftdata.ExecuteStatementInput{
+		Sql:        nullif(stmt.SQL),
+		Parameters: stmt.Parameters,
+	}
+	ctx, span := conn.cfg.startSpan(ctx, "ExecuteStatement", coalesce(params.Sql))
+	_, output, err := conn.executeStatement(ctx, params)
+	endSpan(span, output, err)
+	if err = conn.cfg.afterExecute(ctx, stmt, output, err); err != nil {
+		return nil, err
 	}
-	return params
+	return newResult(conn.cfg, output), nil
 }
 
-func (conn *redshiftDataConn) executeStatement(ctx context.Context, params *redshiftdata.ExecuteStatementInput) (*redshiftdata.GetStatementResultPaginator, *redshiftdata.DescribeStatementOutput, error) {
-	debugLogger.Printf("query: %s", coalesce(params.Sql))
-	params.ClusterIdentifier = conn.cfg.ClusterIdentifier
-	params.Database = conn.cfg.Database
-	params.DbUser = conn.cfg.DbUser
-	params.WorkgroupName = conn.cfg.WorkgroupName
-	params.SecretArn = conn.cfg.SecretsARN
+func (conn *redshiftDataConn) executeStatement(ctx context.Context, params *redshiftdata.ExecuteStatementInput) (resultPaginator, *redshiftdata.DescribeStatementOutput, error) {
+	params.Sql = nullif(conn.cfg.tagSQL(ctx, coalesce(params.Sql)))
+	params.StatementName = conn.cfg.statementName(ctx)
+	sqlHash := sqlHashAttr(coalesce(params.Sql))
+	conn.cfg.logDebug(ctx, "submit statement", append(conn.cfg.sqlLogAttrs(coalesce(params.Sql)), conn.cfg.parameterLogAttrs(params.Parameters)...)...)
+	if params.SessionId == nil {
+		params.ClusterIdentifier = conn.cfg.ClusterIdentifier
+		params.Database = conn.cfg.database(ctx)
+		params.DbUser = conn.cfg.dbUser(ctx)
+		params.WorkgroupName = conn.cfg.WorkgroupName
+		params.SecretArn = conn.cfg.SecretsARN
+	}
+	params.ResultFormat = resultFormat(ctx)
 
-	executeOutput, err := conn.client.ExecuteStatement(ctx, params)
+	ctx, cancel := withProgressCancel(ctx)
+	defer cancel()
+	var executeOutput *redshiftdata.ExecuteStatementOutput
+	done, err := conn.submit(ctx, func(ctx context.Context) (*string, error) {
+		var err error
+		executeOutput, err = conn.client.ExecuteStatement(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		return executeOutput.Id, nil
+	})
 	if err != nil {
-		return nil, nil, fmt.Errorf("execute statement:%w", err)
+		return nil, nil, err
 	}
+	defer done()
 	queryStart := time.Now()
-	debugLogger.Printf("[%s] success execute statement: %s", *executeOutput.Id, coalesce(params.Sql))
+	conn.cfg.logDebug(ctx, "success execute statement", statementIDAttr(executeOutput.Id), sqlHash)
+	addSpanEvent(ctx, "submit", attribute.String("db.redshift.statement_id", coalesce(executeOutput.Id)))
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("db.redshift.statement_id", coalesce(executeOutput.Id)))
 	describeOutput, err := conn.waitWithCancel(ctx, executeOutput.Id, queryStart)
 	if err != nil {
-		return nil, nil, err
+		return nil, describeOutput, err
 	}
 	if describeOutput.Status == types.StatusStringAborted {
-		return nil, nil, fmt.Errorf("query aborted: %s", *describeOutput.Error)
+		return nil, describeOutput, fmt.Errorf("query aborted: %s", coalesce(describeOutput.Error))
 	}
 	if describeOutput.Status == types.StatusStringFailed {
-		return nil, nil, fmt.Errorf("query failed: %s", *describeOutput.Error)
+		return nil, describeOutput, fmt.Errorf("query failed: %s", coalesce(describeOutput.Error))
 	}
 	if describeOutput.Status != types.StatusStringFinished {
-		return nil, nil, fmt.Errorf("query status is not finished: %s", describeOutput.Status)
-	}
-	debugLogger.Printf("[%s] success query: elapsed_time=%s", *executeOutput.Id, time.Since(queryStart))
+		return nil, describeOutput, fmt.Errorf("query status is not finished: %s", describeOutput.Status)
+	}
+	conn.cfg.logDebug(ctx, "statement finished",
+		statementIDAttr(executeOutput.Id),
+		slog.Int64("query_id", describeOutput.RedshiftQueryId),
+		slog.String("status", string(describeOutput.Status)),
+		elapsedAttr(queryStart),
+		sqlHash,
+		slog.Bool("has_result_set", *describeOutput.HasResultSet),
+		slog.Int64("result_rows", describeOutput.ResultRows),
+		slog.Int64("result_size", describeOutput.ResultSize),
+	)
 	if !*describeOutput.HasResultSet {
 		return nil, describeOutput, nil
 	}
-	debugLogger.Printf("[%s] query has result set: result_rows=%d", *executeOutput.Id, describeOutput.ResultRows)
-	p := redshiftdata.NewGetStatementResultPaginator(conn.client, &redshiftdata.GetStatementResultInput{
-		Id: executeOutput.Id,
-	})
-	return p, describeOutput, nil
+	return conn.newResultPaginator(executeOutput.Id, params.ResultFormat), describeOutput, nil
 }
 
-func (conn *redshiftDataConn) batchExecuteStatement(ctx context.Context, params *redshiftdata.BatchExecuteStatementInput) ([]*redshiftdata.GetStatementResultPaginator, *redshiftdata.DescribeStatementOutput, error) {
+func (conn *redshiftDataConn) batchExecuteStatement(ctx context.Context, params *redshiftdata.BatchExecuteStatementInput) ([]resultPaginator, *redshiftdata.DescribeStatementOutput, error) {
+	for i, sql := range params.Sqls {
+		params.Sqls[i] = conn.cfg.tagSQL(ctx, sql)
+	}
+	params.StatementName = conn.cfg.statementName(ctx)
 	params.ClusterIdentifier = conn.cfg.ClusterIdentifier
-	params.Database = conn.cfg.Database
-	params.DbUser = conn.cfg.DbUser
+	params.Database = conn.cfg.database(ctx)
+	params.DbUser = conn.cfg.dbUser(ctx)
 	params.WorkgroupName = conn.cfg.WorkgroupName
 	params.SecretArn = conn.cfg.SecretsARN
+	params.ResultFormat = resultFormat(ctx)
 
-	batchExecuteOutput, err := conn.client.BatchExecuteStatement(ctx, params)
+	ctx, cancel := withProgressCancel(ctx)
+	defer cancel()
+	var batchExecuteOutput *redshiftdata.BatchExecuteStatementOutput
+	done, err := conn.submit(ctx, func(ctx context.Context) (*string, error) {
+		var err error
+		batchExecuteOutput, err = conn.client.BatchExecuteStatement(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		return batchExecuteOutput.Id, nil
+	})
 	if err != nil {
-		return nil, nil, fmt.Errorf("execute statement:%w", err)
+		return nil, nil, err
 	}
+	defer done()
 	queryStart := time.Now()
-	debugLogger.Printf("[%s] success execute statement: %d sqls", *batchExecuteOutput.Id, len(params.Sqls))
+	conn.cfg.logDebug(ctx, "success batch execute statement", statementIDAttr(batchExecuteOutput.Id), slog.Int("sqls", len(params.Sqls)))
+	addSpanEvent(ctx, "submit", attribute.String("db.redshift.statement_id", coalesce(batchExecuteOutput.Id)), attribute.Int("db.redshift.sqls", len(params.Sqls)))
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("db.redshift.statement_id", coalesce(batchExecuteOutput.Id)))
 	describeOutput, err := conn.waitWithCancel(ctx, batchExecuteOutput.Id, queryStart)
 	if err != nil {
-		return nil, nil, err
+		return nil, describeOutput, err
 	}
 	if describeOutput.Status == types.StatusStringAborted {
-		return nil, nil, fmt.Errorf("query aborted: %s", *describeOutput.Error)
+		return nil, describeOutput, fmt.Errorf("query aborted: %s", coalesce(describeOutput.Error))
 	}
 	if describeOutput.Status == types.StatusStringFailed {
-		return nil, nil, fmt.Errorf("query failed: %s", *describeOutput.Error)
+		return nil, describeOutput, fmt.Errorf("query failed: %s", coalesce(describeOutput.Error))
 	}
 	if describeOutput.Status != types.StatusStringFinished {
-		return nil, nil, fmt.Errorf("query status is not finished: %s", describeOutput.Status)
-	}
-	debugLogger.Printf("[%s] success query: elapsed_time=%s", *batchExecuteOutput.Id, time.Since(queryStart))
-	ps := make([]*redshiftdata.GetStatementResultPaginator, len(params.Sqls))
+		return nil, describeOutput, fmt.Errorf("query status is not finished: %s", describeOutput.Status)
+	}
+	conn.cfg.logDebug(ctx, "statement finished",
+		statementIDAttr(batchExecuteOutput.Id),
+		slog.Int64("query_id", describeOutput.RedshiftQueryId),
+		slog.String("status", string(describeOutput.Status)),
+		elapsedAttr(queryStart),
+		slog.Int("sqls", len(params.Sqls)),
+	)
+	ps := make([]resultPaginator, len(params.Sqls))
 	for i, st := range describeOutput.SubStatements {
-		if *st.HasResultSet {
+		if st.HasResultSet == nil || !*st.HasResultSet {
 			continue
 		}
-		ps[i] = redshiftdata.NewGetStatementResultPaginator(conn.client, &redshiftdata.GetStatementResultInput{
-			Id: st.Id,
-		})
+		ps[i] = conn.newResultPaginator(st.Id, params.ResultFormat)
 	}
 	return ps, describeOutput, nil
 }
 
+// submit runs send, which submits a statement and returns its ID, within the active statement limit
+// and the accounting of Shutdown. The statement is tracked as in flight until the returned func is called.
+func (conn *redshiftDataConn) submit(ctx context.Context, send func(ctx context.Context) (*string, error)) (func(), error) {
+	releaseSlot, err := conn.limiter.acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("wait for active statement slot: %w", err)
+	}
+	if err := conns.beginSubmit(); err != nil {
+		releaseSlot()
+		return nil, err
+	}
+	id, err := send(ctx)
+	if err != nil {
+		conns.endSubmit(nil)
+		releaseSlot()
+		return nil, fmt.Errorf("execute statement:%w", err)
+	}
+	var untrack func()
+	var trackErr error
+	err = conns.endSubmit(func() { untrack, trackErr = conn.trackStatement(id) })
+	if err == nil {
+		err = trackErr
+	}
+	if err != nil {
+		// Shutdown or Close finished without seeing the statement, so it is cancelled here.
+		conn.cancelInflight([]string{coalesce(id)})
+		releaseSlot()
+		return nil, err
+	}
+	return func() {
+		untrack()
+		releaseSlot()
+	}, nil
+}
+
 func isFinishedStatus(status types.StatusString) bool {
 	return status == types.StatusStringFinished || status == types.StatusStringFailed || status == types.StatusStringAborted
 }
 
-func (conn *redshiftDataConn) wait(ctx context.Context, id *string, queryStart time.Time) (*redshiftdata.DescribeStatementOutput, error) {
-	timeout := conn.cfg.Timeout
-	if timeout == 0 {
-		timeout = 15 * time.Minute
-	}
-	polling := conn.cfg.Polling
-	if polling == 0 {
-		polling = 10 * time.Millisecond
-	}
+func (conn *redshiftDataConn) wait(ctx context.Context, id *string, queryStart time.Time, stats *statementStats) (*redshiftdata.DescribeStatementOutput, error) {
+	timeout := conn.cfg.queryTimeout(ctx)
+	polling := conn.cfg.polling(ctx)
 	ectx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
-	debugLogger.Printf("[%s] wating finsih query: elapsed_time=%s", *id, time.Since(queryStart))
 	describeOutput, err := conn.client.DescribeStatement(ctx, &redshiftdata.DescribeStatementInput{
 		Id: id,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("describe statement:%w", err)
 	}
-	debugLogger.Printf("[%s] describe statement: status=%s pid=%d query_id=%d", *id, describeOutput.Status, describeOutput.RedshiftPid, describeOutput.RedshiftQueryId)
+	conn.observePoll(ctx, id, describeOutput, queryStart, stats)
 	if isFinishedStatus(describeOutput.Status) {
 		return describeOutput, nil
 	}
@@ -326,22 +559,47 @@ func (conn *redshiftDataConn) wait(ctx context.Context, id *string, queryStart t
 			}
 			return nil, ErrConnClosed
 		}
-		debugLogger.Printf("[%s] wating finsih query: elapsed_time=%s", *id, time.Since(queryStart))
 		describeOutput, err = conn.client.DescribeStatement(ctx, &redshiftdata.DescribeStatementInput{
 			Id: id,
 		})
 		if err != nil {
 			return nil, fmt.Errorf("describe statement:%w", err)
 		}
+		conn.observePoll(ctx, id, describeOutput, queryStart, stats)
 		if isFinishedStatus(describeOutput.Status) {
+			select {
+			case <-conn.aliveCh:
+				// The statement may have been aborted by Close before the poll saw it close.
+				if describeOutput.Status == types.StatusStringAborted {
+					return describeOutput, ErrConnClosed
+				}
+			default:
+			}
 			return describeOutput, nil
 		}
 		delay.Reset(polling)
 	}
 }
 
+func (conn *redshiftDataConn) observePoll(ctx context.Context, id *string, desc *redshiftdata.DescribeStatementOutput, queryStart time.Time, stats *statementStats) {
+	stats.observe(desc)
+	reportProgress(ctx, id, desc, queryStart)
+	addSpanEvent(ctx, "poll",
+		attribute.String("db.redshift.status", string(desc.Status)),
+		attribute.Int64("db.redshift.query_id", desc.RedshiftQueryId),
+	)
+	conn.cfg.logDebug(ctx, "describe statement",
+		statementIDAttr(id),
+		slog.Int64("query_id", desc.RedshiftQueryId),
+		slog.Int64("redshift_pid", desc.RedshiftPid),
+		slog.String("status", string(desc.Status)),
+		elapsedAttr(queryStart),
+	)
+}
+
 func (conn *redshiftDataConn) waitWithCancel(ctx context.Context, id *string, queryStart time.Time) (*redshiftdata.DescribeStatementOutput, error) {
-	desc, err := conn.wait(ctx, id, queryStart)
+	stats := &statementStats{}
+	desc, err := conn.wait(ctx, id, queryStart, stats)
 	cctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	if desc == nil {
@@ -352,20 +610,27 @@ func (conn *redshiftDataConn) waitWithCancel(ctx context.Context, id *string, qu
 		if rErr != nil {
 			return nil, err
 		}
+		stats.observe(desc)
 	}
 	if isFinishedStatus(desc.Status) {
+		conn.recordStatement(ctx, id, desc, desc.Status, queryStart, stats)
+		return desc, err
+	}
+	defer conn.recordStatement(ctx, id, desc, types.StatusStringAborted, queryStart, stats)
+	if errors.Is(err, ErrConnClosed) {
+		// Close cancels the in-flight statements.
 		return desc, err
 	}
-	debugLogger.Printf("[%s] try cancel statement", *id)
+	conn.cfg.logDebug(ctx, "cancel statement", statementIDAttr(id), slog.String("status", string(desc.Status)), elapsedAttr(queryStart))
 	output, cErr := conn.client.CancelStatement(cctx, &redshiftdata.CancelStatementInput{
 		Id: id,
 	})
 	if cErr != nil {
-		errLogger.Printf("[%s] failed cancel statement: %v", *id, err)
+		conn.cfg.logError(ctx, "failed cancel statement", statementIDAttr(id), slog.Any("error", cErr))
 		return desc, err
 	}
 	if !*output.Status {
-		debugLogger.Printf("[%s] cancel statement status is false", *id)
+		conn.cfg.logDebug(ctx, "cancel statement status is false", statementIDAttr(id))
 	}
 	return desc, err
 }
@@ -1,47 +1,169 @@
 package redshiftdatasqldriver
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
 	"github.com/stretchr/testify/require"
 )
 
 func TestRewriteQuery(t *testing.T) {
 	cases := []struct {
-		casename    string
-		query       string
-		paramsCount int
-		expected    string
+		casename string
+		query    string
+		expected string
 	}{
 		{
-			casename:    "no params",
-			query:       `SELECT * FROM pg_user`,
-			paramsCount: 0,
-			expected:    `SELECT * FROM pg_user`,
+			casename: "no params",
+			query:    `SELECT * FROM pg_user`,
+			expected: `SELECT * FROM pg_user`,
 		},
 		{
-			casename:    "no change",
-			query:       `SELECT * FROM pg_user WHERE usename = :name`,
-			paramsCount: 1,
-			expected:    `SELECT * FROM pg_user WHERE usename = :name`,
+			casename: "no change",
+			query:    `SELECT * FROM pg_user WHERE usename = :name`,
+			expected: `SELECT * FROM pg_user WHERE usename = :name`,
 		},
 		{
-			casename:    "? rewrite",
-			query:       `SELECT 'hoge?' FROM pg_user WHERE usename = ? AND usesysid > ?`,
-			paramsCount: 1,
-			expected:    `SELECT 'hoge?' FROM pg_user WHERE usename = :1 AND usesysid > :2`,
+			casename: "? rewrite",
+			query:    `SELECT 'hoge?' FROM pg_user WHERE usename = ? AND usesysid > ?`,
+			expected: `SELECT 'hoge?' FROM pg_user WHERE usename = :1 AND usesysid > :2`,
 		},
 		{
-			casename:    "$ rewrite",
-			query:       `SELECT '3$1$' FROM table WHERE "$column" = $1 AND column1 > $2 AND column2 < $1`,
-			paramsCount: 1,
-			expected:    `SELECT '3$1$' FROM table WHERE "$column" = :1 AND column1 > :2 AND column2 < :1`,
+			casename: "$ rewrite",
+			query:    `SELECT '3$1$' FROM table WHERE "$column" = $1 AND column1 > $2 AND column2 < $1`,
+			expected: `SELECT '3$1$' FROM table WHERE "$column" = :1 AND column1 > :2 AND column2 < :1`,
+		},
+		{
+			casename: "comments",
+			query:    "SELECT ? -- why $1?\n, $1 /* $2 /* nested ? */ ? */ FROM t",
+			expected: "SELECT :1 -- why $1?\n, :1 /* $2 /* nested ? */ ? */ FROM t",
+		},
+		{
+			casename: "dollar quoted",
+			query:    `CREATE PROCEDURE p(a int) AS $body$ BEGIN SELECT $1, '?'; END; $body$ LANGUAGE plpgsql; CALL p($1)`,
+			expected: `CREATE PROCEDURE p(a int) AS $body$ BEGIN SELECT $1, '?'; END; $body$ LANGUAGE plpgsql; CALL p(:1)`,
+		},
+		{
+			casename: "backslash escape in plain literal",
+			query:    `SELECT 'a\'b ?' , ?`,
+			expected: `SELECT 'a\'b ?' , :1`,
+		},
+		{
+			casename: "escapes and doubled quotes",
+			query:    `SELECT E'it\'s ?', 'it''s ?', "a""?" FROM t WHERE x = ?`,
+			expected: `SELECT E'it\'s ?', 'it''s ?', "a""?" FROM t WHERE x = :1`,
+		},
+		{
+			casename: "casts and json operators",
+			query:    `SELECT ?::int, x::text, j ?| array['a'], j ?& array['b'] FROM t WHERE id = ?`,
+			expected: `SELECT :1::int, x::text, j ?| array['a'], j ?& array['b'] FROM t WHERE id = :2`,
 		},
 	}
 	for _, c := range cases {
 		t.Run(c.casename, func(t *testing.T) {
-			actual := rewriteQuery(c.query, c.paramsCount)
+			actual := parseQuery(c.query).rewritten
 			require.Equal(t, c.expected, actual)
 		})
 	}
 }
+
+func TestPing(t *testing.T) {
+	var executed []string
+	client := &mockRedshiftDataClient{
+		ExecuteStatementFunc: func(ctx context.Context, params *redshiftdata.ExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ExecuteStatementOutput, error) {
+			executed = append(executed, *params.Sql)
+			if *params.WorkgroupName == "missing" {
+				return nil, &types.ValidationException{Message: aws.String("workgroup not found")}
+			}
+			return &redshiftdata.ExecuteStatementOutput{Id: aws.String("ping")}, nil
+		},
+		DescribeStatementFunc: func(ctx context.Context, params *redshiftdata.DescribeStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.DescribeStatementOutput, error) {
+			return &redshiftdata.DescribeStatementOutput{
+				Id:           params.Id,
+				Status:       types.StatusStringFinished,
+				HasResultSet: aws.Bool(true),
+			}, nil
+		},
+	}
+	open := func(workgroup string) *sql.DB {
+		cfg := (&RedshiftDataConfig{
+			WorkgroupName: aws.String(workgroup),
+			Database:      aws.String("dev"),
+		}).WithRedshiftDataClientConstructor(func(ctx context.Context, cfg *RedshiftDataConfig) (RedshiftDataClient, error) {
+			return client, nil
+		})
+		return sql.OpenDB(NewConnector(cfg))
+	}
+	restore := requireNoErrorLog(t)
+	defer restore()
+
+	db := open("default")
+	defer db.Close()
+	require.NoError(t, db.PingContext(context.Background()))
+	require.Equal(t, []string{"SELECT 1"}, executed)
+
+	missing := open("missing")
+	defer missing.Close()
+	err := missing.PingContext(context.Background())
+	var ve *types.ValidationException
+	require.ErrorAs(t, err, &ve)
+}
+
+func TestIsValid(t *testing.T) {
+	conn := newConn(&mockRedshiftDataClient{}, &RedshiftDataConfig{})
+	require.True(t, conn.IsValid())
+	require.NoError(t, conn.Close())
+	require.False(t, conn.IsValid())
+	require.ErrorIs(t, conn.Ping(context.Background()), driver.ErrBadConn)
+}
+
+func TestCloseCancelsInflightStatements(t *testing.T) {
+	var mu sync.Mutex
+	var cancelled []string
+	described := make(chan struct{}, 1)
+	client := &mockRedshiftDataClient{
+		ExecuteStatementFunc: func(ctx context.Context, params *redshiftdata.ExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ExecuteStatementOutput, error) {
+			return &redshiftdata.ExecuteStatementOutput{Id: aws.String("long-running")}, nil
+		},
+		DescribeStatementFunc: func(ctx context.Context, params *redshiftdata.DescribeStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.DescribeStatementOutput, error) {
+			select {
+			case described <- struct{}{}:
+			default:
+			}
+			return &redshiftdata.DescribeStatementOutput{
+				Id:     params.Id,
+				Status: types.StatusStringStarted,
+			}, nil
+		},
+		CancelStatementFunc: func(ctx context.Context, params *redshiftdata.CancelStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.CancelStatementOutput, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			cancelled = append(cancelled, *params.Id)
+			return &redshiftdata.CancelStatementOutput{Status: aws.Bool(true)}, nil
+		},
+	}
+	restore := requireNoErrorLog(t)
+	defer restore()
+	conn := newConn(client, &RedshiftDataConfig{Polling: time.Millisecond})
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := conn.ExecContext(context.Background(), `VACUUM`, nil)
+		errCh <- err
+	}()
+	<-described
+	require.NoError(t, conn.Close())
+	mu.Lock()
+	require.Equal(t, []string{"long-running"}, cancelled)
+	mu.Unlock()
+	require.ErrorIs(t, <-errCh, ErrConnClosed)
+	mu.Lock()
+	require.Len(t, cancelled, 1, "the statement is cancelled only once")
+	mu.Unlock()
+}
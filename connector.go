@@ -5,9 +5,22 @@ import (
 	"database/sql/driver"
 )
 
+func NewConnector(cfg *RedshiftDataConfig) driver.Connector {
+	return newConnector(&redshiftDataDriver{}, cfg)
+}
+
+func newConnector(d *redshiftDataDriver, cfg *RedshiftDataConfig) *redshiftDataConnector {
+	return &redshiftDataConnector{
+		d:       d,
+		cfg:     cfg,
+		limiter: cfg.statementLimiter(),
+	}
+}
+
 type redshiftDataConnector struct {
-	d   *redshiftDataDriver
-	cfg *RedshiftDataConfig
+	d       *redshiftDataDriver
+	cfg     *RedshiftDataConfig
+	limiter *StatementLimiter
 }
 
 func (c *redshiftDataConnector) Connect(ctx context.Context) (driver.Conn, error) {
@@ -15,7 +28,9 @@ func (c *redshiftDataConnector) Connect(ctx context.Context) (driver.Conn, error
 	if err != nil {
 		return nil, err
 	}
-	return newConn(client, c.cfg), nil
+	conn := newConn(newMetricsClient(client, c.cfg.MetricsRecorder), c.cfg)
+	conn.limiter = c.limiter
+	return conn, nil
 }
 
 func (c *redshiftDataConnector) Driver() driver.Driver {
@@ -0,0 +1,115 @@
+package redshiftdatasqldriver
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
+)
+
+type statementNameKey struct{}
+
+type queryTagsKey struct{}
+
+type queryTimeoutKey struct{}
+
+type pollingKey struct{}
+
+type dbUserKey struct{}
+
+type databaseKey struct{}
+
+type resultFormatKey struct{}
+
+// WithStatementName sets the StatementName of the statements run with ctx,
+// overriding RedshiftDataConfig.StatementName.
+func WithStatementName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, statementNameKey{}, name)
+}
+
+// WithQueryTags adds tags to the sqlcommenter comment of the statements run with ctx.
+// They are merged over the tags already in ctx and over RedshiftDataConfig.QueryTags.
+func WithQueryTags(ctx context.Context, tags map[string]string) context.Context {
+	merged := make(map[string]string, len(tags))
+	if parent, ok := ctx.Value(queryTagsKey{}).(map[string]string); ok {
+		for k, v := range parent {
+			merged[k] = v
+		}
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, queryTagsKey{}, merged)
+}
+
+func (cfg *RedshiftDataConfig) statementName(ctx context.Context) *string {
+	if name, ok := ctx.Value(statementNameKey{}).(string); ok {
+		return nullif(name)
+	}
+	return nullif(cfg.StatementName)
+}
+
+// WithQueryTimeout overrides RedshiftDataConfig.Timeout for the statements run with ctx.
+func WithQueryTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, queryTimeoutKey{}, timeout)
+}
+
+// WithPolling overrides RedshiftDataConfig.Polling for the statements run with ctx.
+func WithPolling(ctx context.Context, polling time.Duration) context.Context {
+	return context.WithValue(ctx, pollingKey{}, polling)
+}
+
+// WithDbUser overrides RedshiftDataConfig.DbUser for the statements run with ctx.
+func WithDbUser(ctx context.Context, dbUser string) context.Context {
+	return context.WithValue(ctx, dbUserKey{}, dbUser)
+}
+
+// WithDatabase overrides RedshiftDataConfig.Database for the statements run with ctx.
+func WithDatabase(ctx context.Context, database string) context.Context {
+	return context.WithValue(ctx, databaseKey{}, database)
+}
+
+// WithResultFormat sets the ResultFormat of the statements run with ctx.
+// CSV results are read with GetStatementResultV2.
+func WithResultFormat(ctx context.Context, format types.ResultFormatString) context.Context {
+	return context.WithValue(ctx, resultFormatKey{}, format)
+}
+
+func (cfg *RedshiftDataConfig) queryTimeout(ctx context.Context) time.Duration {
+	if timeout, ok := ctx.Value(queryTimeoutKey{}).(time.Duration); ok && timeout > 0 {
+		return timeout
+	}
+	if cfg.Timeout > 0 {
+		return cfg.Timeout
+	}
+	return 15 * time.Minute
+}
+
+func (cfg *RedshiftDataConfig) polling(ctx context.Context) time.Duration {
+	if polling, ok := ctx.Value(pollingKey{}).(time.Duration); ok && polling > 0 {
+		return polling
+	}
+	if cfg.Polling > 0 {
+		return cfg.Polling
+	}
+	return 10 * time.Millisecond
+}
+
+func (cfg *RedshiftDataConfig) dbUser(ctx context.Context) *string {
+	if dbUser, ok := ctx.Value(dbUserKey{}).(string); ok && dbUser != "" {
+		return &dbUser
+	}
+	return cfg.DbUser
+}
+
+func (cfg *RedshiftDataConfig) database(ctx context.Context) *string {
+	if database, ok := ctx.Value(databaseKey{}).(string); ok && database != "" {
+		return &database
+	}
+	return cfg.Database
+}
+
+func resultFormat(ctx context.Context) types.ResultFormatString {
+	format, _ := ctx.Value(resultFormatKey{}).(types.ResultFormatString)
+	return format
+}
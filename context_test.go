@@ -0,0 +1,134 @@
+package redshiftdatasqldriver
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
+	"github.com/mashiike/redshift-data-sql-driver/redshiftdatamock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextOverrides(t *testing.T) {
+	var inputs []*redshiftdata.ExecuteStatementInput
+	var cancelled []string
+	client := &mockRedshiftDataClient{
+		ExecuteStatementFunc: func(ctx context.Context, params *redshiftdata.ExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ExecuteStatementOutput, error) {
+			inputs = append(inputs, params)
+			return &redshiftdata.ExecuteStatementOutput{Id: params.Sql}, nil
+		},
+		DescribeStatementFunc: func(ctx context.Context, params *redshiftdata.DescribeStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.DescribeStatementOutput, error) {
+			status := types.StatusStringFinished
+			if *params.Id == "SELECT slow" {
+				status = types.StatusStringStarted
+			}
+			return &redshiftdata.DescribeStatementOutput{
+				Id:           params.Id,
+				Status:       status,
+				HasResultSet: aws.Bool(false),
+			}, nil
+		},
+		CancelStatementFunc: func(ctx context.Context, params *redshiftdata.CancelStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.CancelStatementOutput, error) {
+			cancelled = append(cancelled, *params.Id)
+			return &redshiftdata.CancelStatementOutput{Status: aws.Bool(true)}, nil
+		},
+	}
+	cfg := (&RedshiftDataConfig{
+		ClusterIdentifier: aws.String("default"),
+		DbUser:            aws.String("admin"),
+		Database:          aws.String("dev"),
+		Timeout:           time.Minute,
+	}).WithRedshiftDataClientConstructor(func(ctx context.Context, cfg *RedshiftDataConfig) (RedshiftDataClient, error) {
+		return client, nil
+	})
+	db := sql.OpenDB(NewConnector(cfg))
+	defer db.Close()
+	restore := requireNoErrorLog(t)
+	defer restore()
+
+	_, err := db.ExecContext(context.Background(), `DELETE FROM a`)
+	require.NoError(t, err)
+	ctx := WithDbUser(WithDatabase(context.Background(), "analytics"), "reporter")
+	_, err = db.ExecContext(ctx, `DELETE FROM b`)
+	require.NoError(t, err)
+	require.Len(t, inputs, 2)
+	require.Equal(t, "dev", *inputs[0].Database)
+	require.Equal(t, "admin", *inputs[0].DbUser)
+	require.Equal(t, "analytics", *inputs[1].Database)
+	require.Equal(t, "reporter", *inputs[1].DbUser)
+	require.Equal(t, "dev", *cfg.Database)
+
+	ctx = WithPolling(WithQueryTimeout(context.Background(), 50*time.Millisecond), 5*time.Millisecond)
+	start := time.Now()
+	_, err = db.ExecContext(ctx, `SELECT slow`)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Less(t, time.Since(start), 10*time.Second)
+	require.Equal(t, []string{"SELECT slow"}, cancelled)
+}
+
+func TestCSVResultFormat(t *testing.T) {
+	client := redshiftdatamock.New()
+	client.ExpectExecute(`SELECT id, name, active, code FROM users`).WillReturnRows(
+		redshiftdatamock.NewRows("id", "name", "active", "code").
+			WithColumnTypes("int8", "varchar", "bool", "varchar").
+			WithNotNull("code").
+			WithPageSize(2).
+			AddRow(1, "hoge, jr", true, "a").
+			AddRow(2, nil, false, "").
+			AddRow(3, "piyo", true, "c"),
+	)
+	db := openTestDB(t, client, withFastPolling)
+	restore := requireNoErrorLog(t)
+	defer restore()
+
+	rows, err := db.QueryContext(WithResultFormat(context.Background(), types.ResultFormatStringCsv), `SELECT id, name, active, code FROM users`)
+	require.NoError(t, err)
+	defer rows.Close()
+	type user struct {
+		id     int64
+		name   sql.NullString
+		active bool
+		code   sql.NullString
+	}
+	var users []user
+	for rows.Next() {
+		var u user
+		require.NoError(t, rows.Scan(&u.id, &u.name, &u.active, &u.code))
+		users = append(users, u)
+	}
+	require.NoError(t, rows.Err())
+	require.Equal(t, []user{
+		{id: 1, name: sql.NullString{String: "hoge, jr", Valid: true}, active: true, code: sql.NullString{String: "a", Valid: true}},
+		{id: 2, active: false, code: sql.NullString{String: "", Valid: true}},
+		{id: 3, name: sql.NullString{String: "piyo", Valid: true}, active: true, code: sql.NullString{String: "c", Valid: true}},
+	}, users)
+	require.Equal(t, 2, client.CallCount(redshiftdatamock.OperationGetStatementResultV2))
+	require.Equal(t, 0, client.CallCount(redshiftdatamock.OperationGetStatementResult))
+}
+
+func TestCSVResultHeader(t *testing.T) {
+	client := redshiftdatamock.New()
+	client.ExpectExecute(`SELECT name FROM t`).WillReturnRows(
+		redshiftdatamock.NewRows("name").WithPageSize(1).AddRow("name").AddRow("name"),
+	)
+	output, err := client.ExecuteStatement(context.Background(), &redshiftdata.ExecuteStatementInput{
+		Sql:          aws.String(`SELECT name FROM t`),
+		ResultFormat: types.ResultFormatStringCsv,
+	})
+	require.NoError(t, err)
+	p := &csvResultPaginator{client: client, id: output.Id}
+	var names []string
+	for p.HasMorePages() {
+		output, err := p.NextPage(context.Background())
+		require.NoError(t, err)
+		for _, record := range output.Records {
+			names = append(names, record[0].(*types.FieldMemberStringValue).Value)
+		}
+	}
+	// Only the first line of the first page is the header, even when a row looks the same.
+	require.Equal(t, []string{"name", "name"}, names)
+}
@@ -25,8 +25,5 @@ func (d *redshiftDataDriver) OpenConnector(dsn string) (driver.Connector, error)
 	if err != nil {
 		return nil, err
 	}
-	return &redshiftDataConnector{
-		d:   d,
-		cfg: cfg,
-	}, nil
+	return newConnector(d, cfg), nil
 }
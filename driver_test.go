@@ -411,3 +411,37 @@ func TestMockSuccess(t *testing.T) {
 		}, actual)
 	})
 }
+
+func TestNewConnectorWithClientConstructor(t *testing.T) {
+	client := &mockRedshiftDataClient{
+		ExecuteStatementFunc: func(ctx context.Context, params *redshiftdata.ExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ExecuteStatementOutput, error) {
+			return &redshiftdata.ExecuteStatementOutput{
+				Id: aws.String("dummy"),
+			}, nil
+		},
+		DescribeStatementFunc: func(ctx context.Context, params *redshiftdata.DescribeStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.DescribeStatementOutput, error) {
+			return &redshiftdata.DescribeStatementOutput{
+				Id:           aws.String("dummy"),
+				Status:       types.StatusStringFinished,
+				HasResultSet: aws.Bool(false),
+				ResultRows:   2,
+			}, nil
+		},
+	}
+	cfg := (&RedshiftDataConfig{
+		WorkgroupName: aws.String("default"),
+		Database:      aws.String("dev"),
+		Params:        url.Values{"mock": []string{"not_registered"}},
+	}).WithRedshiftDataClientConstructor(func(ctx context.Context, cfg *RedshiftDataConfig) (RedshiftDataClient, error) {
+		return client, nil
+	})
+	db := sql.OpenDB(NewConnector(cfg))
+	defer db.Close()
+	restore := requireNoErrorLog(t)
+	defer restore()
+	result, err := db.ExecContext(context.Background(), `DELETE FROM users`)
+	require.NoError(t, err)
+	rowsAffected, err := result.RowsAffected()
+	require.NoError(t, err)
+	require.Equal(t, int64(2), rowsAffected)
+}
@@ -1,14 +1,18 @@
 package redshiftdatasqldriver
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type RedshiftDataConfig struct {
@@ -18,11 +22,33 @@ type RedshiftDataConfig struct {
 	WorkgroupName     *string
 	SecretsARN        *string
 
-	Timeout time.Duration
-	Polling time.Duration
+	Timeout             time.Duration
+	Polling             time.Duration
+	MaxActiveStatements int
+	SessionTransactions bool
 
 	Params             url.Values
 	RedshiftDataOptFns []func(*redshiftdata.Options)
+
+	RedshiftDataClientConstructor func(ctx context.Context, cfg *RedshiftDataConfig) (RedshiftDataClient, error)
+
+	Logger      Logger
+	DebugLogger Logger
+	SlogHandler slog.Handler
+
+	SQLLogMode         SQLLogMode
+	SQLLogMaxLength    int
+	LogParameterValues bool
+
+	TracerProvider  trace.TracerProvider
+	MetricsRecorder MetricsRecorder
+
+	Hooks []*Hook
+
+	StatementName string
+	QueryTags     map[string]string
+
+	StatementLimiter *StatementLimiter
 }
 
 func (cfg *RedshiftDataConfig) String() string {
@@ -44,6 +70,16 @@ func (cfg *RedshiftDataConfig) String() string {
 	} else {
 		params.Del("polling")
 	}
+	if cfg.MaxActiveStatements != 0 {
+		params.Add("max_active_statements", strconv.Itoa(cfg.MaxActiveStatements))
+	} else {
+		params.Del("max_active_statements")
+	}
+	if cfg.SessionTransactions {
+		params.Set("session_transactions", "true")
+	} else {
+		params.Del("session_transactions")
+	}
 	encodedParams := params.Encode()
 	if encodedParams != "" {
 		return base + "?" + encodedParams
@@ -68,6 +104,20 @@ func (cfg *RedshiftDataConfig) setParams(params url.Values) error {
 		}
 		cfg.Params.Del("polling")
 	}
+	if params.Has("max_active_statements") {
+		cfg.MaxActiveStatements, err = strconv.Atoi(params.Get("max_active_statements"))
+		if err != nil {
+			return fmt.Errorf("parse max_active_statements as int: %w", err)
+		}
+		cfg.Params.Del("max_active_statements")
+	}
+	if params.Has("session_transactions") {
+		cfg.SessionTransactions, err = strconv.ParseBool(params.Get("session_transactions"))
+		if err != nil {
+			return fmt.Errorf("parse session_transactions as bool: %w", err)
+		}
+		cfg.Params.Del("session_transactions")
+	}
 	if params.Has("region") {
 		cfg = cfg.WithRegion(params.Get("region"))
 	}
@@ -151,3 +201,78 @@ func (cfg *RedshiftDataConfig) WithRegion(region string) *RedshiftDataConfig {
 	})
 	return cfg
 }
+
+func (cfg *RedshiftDataConfig) WithRedshiftDataClientConstructor(fn func(ctx context.Context, cfg *RedshiftDataConfig) (RedshiftDataClient, error)) *RedshiftDataConfig {
+	cfg.RedshiftDataClientConstructor = fn
+	return cfg
+}
+
+func (cfg *RedshiftDataConfig) WithLogger(l Logger) *RedshiftDataConfig {
+	cfg.Logger = l
+	return cfg
+}
+
+func (cfg *RedshiftDataConfig) WithDebugLogger(l Logger) *RedshiftDataConfig {
+	cfg.DebugLogger = l
+	return cfg
+}
+
+func (cfg *RedshiftDataConfig) WithSlogHandler(h slog.Handler) *RedshiftDataConfig {
+	cfg.SlogHandler = h
+	return cfg
+}
+
+func (cfg *RedshiftDataConfig) WithSQLLogMode(mode SQLLogMode) *RedshiftDataConfig {
+	cfg.SQLLogMode = mode
+	return cfg
+}
+
+func (cfg *RedshiftDataConfig) WithSQLLogMaxLength(n int) *RedshiftDataConfig {
+	cfg.SQLLogMaxLength = n
+	return cfg
+}
+
+func (cfg *RedshiftDataConfig) WithLogParameterValues(allow bool) *RedshiftDataConfig {
+	cfg.LogParameterValues = allow
+	return cfg
+}
+
+func (cfg *RedshiftDataConfig) WithTracerProvider(tp trace.TracerProvider) *RedshiftDataConfig {
+	cfg.TracerProvider = tp
+	return cfg
+}
+
+func (cfg *RedshiftDataConfig) WithMetricsRecorder(r MetricsRecorder) *RedshiftDataConfig {
+	cfg.MetricsRecorder = r
+	return cfg
+}
+
+func (cfg *RedshiftDataConfig) WithStatementName(name string) *RedshiftDataConfig {
+	cfg.StatementName = name
+	return cfg
+}
+
+func (cfg *RedshiftDataConfig) WithQueryTags(tags map[string]string) *RedshiftDataConfig {
+	cfg.QueryTags = tags
+	return cfg
+}
+
+func (cfg *RedshiftDataConfig) WithMaxActiveStatements(n int) *RedshiftDataConfig {
+	cfg.MaxActiveStatements = n
+	return cfg
+}
+
+func (cfg *RedshiftDataConfig) WithSessionTransactions(enabled bool) *RedshiftDataConfig {
+	cfg.SessionTransactions = enabled
+	return cfg
+}
+
+func (cfg *RedshiftDataConfig) WithStatementLimiter(l *StatementLimiter) *RedshiftDataConfig {
+	cfg.StatementLimiter = l
+	return cfg
+}
+
+func (cfg *RedshiftDataConfig) WithHooks(hooks ...*Hook) *RedshiftDataConfig {
+	cfg.Hooks = append(cfg.Hooks, hooks...)
+	return cfg
+}
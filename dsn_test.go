@@ -44,6 +44,22 @@ func TestRedshiftDataConfig__String(t *testing.T) {
 			},
 			expected: "admin@cluster(default)/dev?polling=5ms",
 		},
+		{
+			dsn: &RedshiftDataConfig{
+				WorkgroupName:       aws.String("default"),
+				Database:            aws.String("dev"),
+				MaxActiveStatements: 10,
+			},
+			expected: "workgroup(default)/dev?max_active_statements=10",
+		},
+		{
+			dsn: &RedshiftDataConfig{
+				WorkgroupName:       aws.String("default"),
+				Database:            aws.String("dev"),
+				SessionTransactions: true,
+			},
+			expected: "workgroup(default)/dev?session_transactions=true",
+		},
 		{
 			dsn: &RedshiftDataConfig{
 				ClusterIdentifier: aws.String("default"),
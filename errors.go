@@ -9,4 +9,10 @@ var (
 	ErrBeforeCommit = errors.New("transaction is not committed")
 	ErrNotInTx      = errors.New("not in transaction")
 	ErrInTx         = errors.New("already in transaction")
+	ErrShutdown     = errors.New("driver is shut down")
+	ErrTxTooLarge   = errors.New("transaction exceeds the BatchExecuteStatement limits")
+
+	ErrMissingArgument   = errors.New("missing argument")
+	ErrUnusedArgument    = errors.New("unused argument")
+	ErrDuplicateArgument = errors.New("duplicate argument")
 )
@@ -0,0 +1,169 @@
+package redshiftdatasqldriver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
+)
+
+// StatementHistory lists the statements submitted to the cluster or workgroup of a DSN
+// with the Data API ListStatements operation, to find statements that are still running
+// and cancel them or wait for them to finish.
+//
+// The client made by DefaultRedshiftDataClientConstructor satisfies redshiftdata.ListStatementsAPIClient;
+// with other clients the listing returns ErrNotSupported.
+type StatementHistory struct {
+	client RedshiftDataClient
+	cfg    *RedshiftDataConfig
+}
+
+type ListStatementsOptions struct {
+	// Status lists only the statements in this status, such as STARTED. default = all statuses
+	Status types.StatusString
+	// StatementName lists only the statements whose name starts with this prefix, case sensitively.
+	StatementName string
+	// CurrentSessionOnly lists only the statements of the caller's IAM session instead of all of its IAM role.
+	CurrentSessionOnly bool
+	// PageSize is the number of statements per API call. default = the Data API's
+	PageSize int32
+	// Limit stops the listing after this many statements. default = unlimited
+	Limit int
+}
+
+type StatementSummary struct {
+	ID   string
+	Name string
+	// Status is SUBMITTED, PICKED, STARTED, FINISHED, ABORTED or FAILED.
+	Status types.StatusString
+	// Sqls holds the SQL of the statement, or of each statement of a batch.
+	Sqls      []string
+	IsBatch   bool
+	SessionID string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Finished reports whether the statement is FINISHED, ABORTED or FAILED.
+func (s *StatementSummary) Finished() bool {
+	return isFinishedStatus(s.Status)
+}
+
+// NewStatementHistory returns the StatementHistory of the DSN db was opened with.
+func NewStatementHistory(ctx context.Context, db *sql.DB) (*StatementHistory, error) {
+	client, cfg, err := rawClient(ctx, db)
+	if err != nil {
+		return nil, fmt.Errorf("statement history: %w", err)
+	}
+	return &StatementHistory{client: client, cfg: cfg}, nil
+}
+
+// NewConnectorStatementHistory returns the StatementHistory of a connector made by NewConnector.
+func NewConnectorStatementHistory(ctx context.Context, connector driver.Connector) (*StatementHistory, error) {
+	client, cfg, err := connectorClient(ctx, connector)
+	if err != nil {
+		return nil, fmt.Errorf("statement history: %w", err)
+	}
+	return &StatementHistory{client: client, cfg: cfg}, nil
+}
+
+// List lists the statements run on the database of the DSN, most recent first.
+func (h *StatementHistory) List(ctx context.Context, opts *ListStatementsOptions) ([]StatementSummary, error) {
+	client, ok := h.client.(redshiftdata.ListStatementsAPIClient)
+	if !ok {
+		return nil, fmt.Errorf("statement history: ListStatements %w", ErrNotSupported)
+	}
+	if opts == nil {
+		opts = &ListStatementsOptions{}
+	}
+	p := redshiftdata.NewListStatementsPaginator(client, &redshiftdata.ListStatementsInput{
+		ClusterIdentifier: h.cfg.ClusterIdentifier,
+		Database:          h.cfg.database(ctx),
+		WorkgroupName:     h.cfg.WorkgroupName,
+		Status:            opts.Status,
+		StatementName:     nullif(opts.StatementName),
+		RoleLevel:         aws.Bool(!opts.CurrentSessionOnly),
+		MaxResults:        opts.PageSize,
+	})
+	var statements []StatementSummary
+	for p.HasMorePages() && !limitReached(len(statements), opts.Limit) {
+		output, err := p.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list statements: %w", err)
+		}
+		for _, s := range output.Statements {
+			summary := StatementSummary{
+				ID:        coalesce(s.Id),
+				Name:      coalesce(s.StatementName),
+				Status:    s.Status,
+				Sqls:      s.QueryStrings,
+				IsBatch:   aws.ToBool(s.IsBatchStatement),
+				SessionID: coalesce(s.SessionId),
+				CreatedAt: aws.ToTime(s.CreatedAt),
+				UpdatedAt: aws.ToTime(s.UpdatedAt),
+			}
+			if s.QueryString != nil {
+				summary.Sqls = []string{*s.QueryString}
+			}
+			statements = append(statements, summary)
+		}
+	}
+	return truncate(statements, opts.Limit), nil
+}
+
+// Cancel cancels the statement with id.
+func (h *StatementHistory) Cancel(ctx context.Context, id string) error {
+	output, err := h.client.CancelStatement(ctx, &redshiftdata.CancelStatementInput{
+		Id: aws.String(id),
+	})
+	if err != nil {
+		return fmt.Errorf("cancel statement %s: %w", id, err)
+	}
+	if !aws.ToBool(output.Status) {
+		return fmt.Errorf("cancel statement %s: not cancelled", id)
+	}
+	h.cfg.logDebug(ctx, "cancel statement", statementIDAttr(&id))
+	return nil
+}
+
+// Wait polls the statement with id until it is FINISHED, ABORTED or FAILED and returns its description,
+// with an error if the statement did not finish. It waits at most the query timeout of the DSN
+// and, unlike the queries of the driver, leaves the statement running when ctx is done.
+func (h *StatementHistory) Wait(ctx context.Context, id string) (*redshiftdata.DescribeStatementOutput, error) {
+	ectx, cancel := context.WithTimeout(ctx, h.cfg.queryTimeout(ctx))
+	defer cancel()
+	polling := h.cfg.polling(ctx)
+	for {
+		desc, err := h.client.DescribeStatement(ectx, &redshiftdata.DescribeStatementInput{
+			Id: aws.String(id),
+		})
+		if err != nil {
+			if ectx.Err() != nil {
+				return nil, ectx.Err()
+			}
+			return nil, fmt.Errorf("describe statement %s: %w", id, err)
+		}
+		h.cfg.logDebug(ctx, "describe statement", statementIDAttr(&id), slog.String("status", string(desc.Status)))
+		switch desc.Status {
+		case types.StatusStringFinished:
+			return desc, nil
+		case types.StatusStringAborted:
+			return desc, fmt.Errorf("query aborted: %s", coalesce(desc.Error))
+		case types.StatusStringFailed:
+			return desc, fmt.Errorf("query failed: %s", coalesce(desc.Error))
+		}
+		delay := time.NewTimer(polling)
+		select {
+		case <-ectx.Done():
+			delay.Stop()
+			return nil, ectx.Err()
+		case <-delay.C:
+		}
+	}
+}
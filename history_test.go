@@ -0,0 +1,182 @@
+package redshiftdatasqldriver
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
+	"github.com/mashiike/redshift-data-sql-driver/redshiftdatamock"
+	"github.com/stretchr/testify/require"
+)
+
+// historyRecordingClient records the ListStatements inputs.
+type historyRecordingClient struct {
+	*redshiftdatamock.Client
+	inputs []*redshiftdata.ListStatementsInput
+}
+
+func (c *historyRecordingClient) ListStatements(ctx context.Context, params *redshiftdata.ListStatementsInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ListStatementsOutput, error) {
+	c.inputs = append(c.inputs, params)
+	return c.Client.ListStatements(ctx, params, optFns...)
+}
+
+func TestStatementHistory(t *testing.T) {
+	client := &historyRecordingClient{Client: redshiftdatamock.New()}
+	client.ExpectExecute(`VACUUM users`).WithLatency(time.Hour)
+	client.ExpectBatchExecute(`DELETE FROM users`, `INSERT INTO users SELECT * FROM staging`).WithLatency(time.Hour)
+	client.ExpectExecute(`ANALYZE users`)
+	cfg := (&RedshiftDataConfig{
+		ClusterIdentifier: aws.String("my-cluster"),
+		Database:          aws.String("dev"),
+		Polling:           time.Millisecond,
+	}).WithRedshiftDataClientConstructor(func(ctx context.Context, cfg *RedshiftDataConfig) (RedshiftDataClient, error) {
+		return client, nil
+	})
+	db := sql.OpenDB(NewConnector(cfg))
+	defer db.Close()
+	restore := requireNoErrorLog(t)
+	defer restore()
+	ctx := context.Background()
+
+	load, err := client.ExecuteStatement(ctx, &redshiftdata.ExecuteStatementInput{
+		Sql:                     aws.String(`VACUUM users`),
+		StatementName:           aws.String("etl-load"),
+		SessionKeepAliveSeconds: aws.Int32(60),
+	})
+	require.NoError(t, err)
+	batch, err := client.BatchExecuteStatement(ctx, &redshiftdata.BatchExecuteStatementInput{
+		Sqls:          []string{`DELETE FROM users`, `INSERT INTO users SELECT * FROM staging`},
+		StatementName: aws.String("etl-batch"),
+	})
+	require.NoError(t, err)
+	analyze, err := client.ExecuteStatement(ctx, &redshiftdata.ExecuteStatementInput{
+		Sql:           aws.String(`ANALYZE users`),
+		StatementName: aws.String("etl-analyze"),
+	})
+	require.NoError(t, err)
+
+	history, err := NewStatementHistory(ctx, db)
+	require.NoError(t, err)
+	opts := &ListStatementsOptions{
+		Status:             types.StatusStringStarted,
+		StatementName:      "etl-",
+		CurrentSessionOnly: true,
+		PageSize:           1,
+	}
+	statements, err := history.List(ctx, opts)
+	require.NoError(t, err)
+	require.Len(t, statements, 2)
+	require.Equal(t, aws.ToString(batch.Id), statements[0].ID)
+	require.Equal(t, "etl-batch", statements[0].Name)
+	require.Equal(t, []string{`DELETE FROM users`, `INSERT INTO users SELECT * FROM staging`}, statements[0].Sqls)
+	require.True(t, statements[0].IsBatch)
+	require.Equal(t, aws.ToString(load.Id), statements[1].ID)
+	require.Equal(t, "etl-load", statements[1].Name)
+	require.Equal(t, types.StatusStringStarted, statements[1].Status)
+	require.Equal(t, []string{`VACUUM users`}, statements[1].Sqls)
+	require.False(t, statements[1].IsBatch)
+	require.Equal(t, aws.ToString(load.SessionId), statements[1].SessionID)
+	require.False(t, statements[1].Finished())
+	require.Len(t, client.inputs, 2)
+	input := client.inputs[0]
+	require.Equal(t, "my-cluster", aws.ToString(input.ClusterIdentifier))
+	require.Nil(t, input.WorkgroupName)
+	require.Equal(t, "dev", aws.ToString(input.Database))
+	require.Equal(t, types.StatusStringStarted, input.Status)
+	require.Equal(t, "etl-", aws.ToString(input.StatementName))
+	require.False(t, aws.ToBool(input.RoleLevel))
+
+	opts.Limit = 1
+	statements, err = history.List(ctx, opts)
+	require.NoError(t, err)
+	require.Len(t, statements, 1)
+	require.Equal(t, aws.ToString(batch.Id), statements[0].ID)
+
+	require.NoError(t, history.Cancel(ctx, aws.ToString(load.Id)))
+	desc, err := history.Wait(ctx, aws.ToString(load.Id))
+	require.EqualError(t, err, "query aborted: Query cancelled by user.")
+	require.Equal(t, types.StatusStringAborted, desc.Status)
+
+	desc, err = history.Wait(ctx, aws.ToString(analyze.Id))
+	require.NoError(t, err)
+	require.Equal(t, types.StatusStringFinished, desc.Status)
+}
+
+func TestStatementHistoryWaitFailed(t *testing.T) {
+	client := &mockRedshiftDataClient{
+		DescribeStatementFunc: func(ctx context.Context, params *redshiftdata.DescribeStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.DescribeStatementOutput, error) {
+			return &redshiftdata.DescribeStatementOutput{Id: params.Id, Status: types.StatusStringFailed, Error: aws.String("division by zero")}, nil
+		},
+	}
+	cfg := (&RedshiftDataConfig{
+		WorkgroupName: aws.String("default"),
+	}).WithRedshiftDataClientConstructor(func(ctx context.Context, cfg *RedshiftDataConfig) (RedshiftDataClient, error) {
+		return client, nil
+	})
+	history, err := NewConnectorStatementHistory(context.Background(), NewConnector(cfg))
+	require.NoError(t, err)
+	desc, err := history.Wait(context.Background(), "stmt-1")
+	require.EqualError(t, err, "query failed: division by zero")
+	require.Equal(t, types.StatusStringFailed, desc.Status)
+}
+
+func TestStatementHistoryWaitTimeout(t *testing.T) {
+	client := &mockRedshiftDataClient{
+		DescribeStatementFunc: func(ctx context.Context, params *redshiftdata.DescribeStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.DescribeStatementOutput, error) {
+			return &redshiftdata.DescribeStatementOutput{Id: params.Id, Status: types.StatusStringStarted}, nil
+		},
+		CancelStatementFunc: func(ctx context.Context, params *redshiftdata.CancelStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.CancelStatementOutput, error) {
+			t.Error("Wait must not cancel the statement")
+			return nil, nil
+		},
+	}
+	cfg := (&RedshiftDataConfig{
+		WorkgroupName: aws.String("default"),
+		Timeout:       20 * time.Millisecond,
+		Polling:       time.Millisecond,
+	}).WithRedshiftDataClientConstructor(func(ctx context.Context, cfg *RedshiftDataConfig) (RedshiftDataClient, error) {
+		return client, nil
+	})
+	history, err := NewConnectorStatementHistory(context.Background(), NewConnector(cfg))
+	require.NoError(t, err)
+	_, err = history.Wait(context.Background(), "stmt-1")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestConnectorStatementHistory(t *testing.T) {
+	client := &historyRecordingClient{Client: redshiftdatamock.New()}
+	recorder := &testMetricsRecorder{}
+	cfg := (&RedshiftDataConfig{
+		WorkgroupName:   aws.String("default"),
+		MetricsRecorder: recorder,
+	}).WithRedshiftDataClientConstructor(func(ctx context.Context, cfg *RedshiftDataConfig) (RedshiftDataClient, error) {
+		return client, nil
+	})
+	history, err := NewConnectorStatementHistory(context.Background(), NewConnector(cfg))
+	require.NoError(t, err)
+	statements, err := history.List(context.Background(), nil)
+	require.NoError(t, err)
+	require.Empty(t, statements)
+	require.Equal(t, 1, recorder.apiCalls["ListStatements"])
+	require.Len(t, client.inputs, 1)
+	require.Equal(t, "default", aws.ToString(client.inputs[0].WorkgroupName))
+	require.Empty(t, client.inputs[0].Status)
+	require.Nil(t, client.inputs[0].StatementName)
+	require.True(t, aws.ToBool(client.inputs[0].RoleLevel))
+}
+
+func TestStatementHistoryNotSupported(t *testing.T) {
+	cfg := (&RedshiftDataConfig{
+		WorkgroupName: aws.String("default"),
+	}).WithRedshiftDataClientConstructor(func(ctx context.Context, cfg *RedshiftDataConfig) (RedshiftDataClient, error) {
+		return struct{ RedshiftDataClient }{&mockRedshiftDataClient{}}, nil
+	})
+	history, err := NewConnectorStatementHistory(context.Background(), NewConnector(cfg))
+	require.NoError(t, err)
+	_, err = history.List(context.Background(), nil)
+	require.ErrorIs(t, err, ErrNotSupported)
+}
@@ -0,0 +1,88 @@
+package redshiftdatasqldriver
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
+)
+
+// HookKind tells which driver call a HookStatement comes from.
+type HookKind string
+
+const (
+	HookKindExec   HookKind = "exec"
+	HookKindQuery  HookKind = "query"
+	HookKindCommit HookKind = "commit"
+	HookKindScript HookKind = "script"
+)
+
+// HookStatement is the statement about to be sent to the Data API.
+// BeforeExecute hooks may modify SQL, Parameters and Sqls in place.
+type HookStatement struct {
+	Kind HookKind
+	// SQL and Parameters are set for exec and query, after placeholder rewriting.
+	SQL        string
+	Parameters []types.SqlParameter
+	// Sqls holds the statements queued in the transaction for commit,
+	// or one batch of the statements of a script.
+	Sqls []string
+}
+
+// Hook intercepts statements on a connector. Any of the functions may be nil.
+type Hook struct {
+	// BeforeExecute is called before the statement is submitted.
+	// Returning an error cancels the call without contacting AWS.
+	BeforeExecute func(ctx context.Context, stmt *HookStatement) (context.Context, error)
+	// AfterExecute is called once the statement ends, with the last DescribeStatement output
+	// (nil if the statement was never described) and the error the caller would receive.
+	// The returned error replaces it, except that an error without desc is kept,
+	// since there is no result to return for a statement that was never described.
+	AfterExecute func(ctx context.Context, stmt *HookStatement, desc *redshiftdata.DescribeStatementOutput, err error) error
+	// OnRow is called for every row read from a query result; it may modify row in place.
+	OnRow func(ctx context.Context, stmt *HookStatement, columns []string, row []driver.Value) error
+}
+
+var errHookChangedStatements = errors.New("hook changed the number of statements in transaction")
+
+func (cfg *RedshiftDataConfig) beforeExecute(ctx context.Context, stmt *HookStatement) (context.Context, error) {
+	for _, hook := range cfg.Hooks {
+		if hook == nil || hook.BeforeExecute == nil {
+			continue
+		}
+		var err error
+		ctx, err = hook.BeforeExecute(ctx, stmt)
+		if err != nil {
+			return ctx, err
+		}
+	}
+	return ctx, nil
+}
+
+func (cfg *RedshiftDataConfig) afterExecute(ctx context.Context, stmt *HookStatement, desc *redshiftdata.DescribeStatementOutput, err error) error {
+	orig := err
+	for _, hook := range cfg.Hooks {
+		if hook == nil || hook.AfterExecute == nil {
+			continue
+		}
+		err = hook.AfterExecute(ctx, stmt, desc, err)
+	}
+	if err == nil && desc == nil {
+		return orig
+	}
+	return err
+}
+
+func (cfg *RedshiftDataConfig) onRow(ctx context.Context, stmt *HookStatement, columns []string, row []driver.Value) error {
+	for _, hook := range cfg.Hooks {
+		if hook == nil || hook.OnRow == nil {
+			continue
+		}
+		if err := hook.OnRow(ctx, stmt, columns, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,262 @@
+package redshiftdatasqldriver
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
+	"github.com/stretchr/testify/require"
+)
+
+func newHookTestClient(executed *[]string) *mockRedshiftDataClient {
+	return &mockRedshiftDataClient{
+		ExecuteStatementFunc: func(ctx context.Context, params *redshiftdata.ExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ExecuteStatementOutput, error) {
+			*executed = append(*executed, coalesce(params.Sql))
+			return &redshiftdata.ExecuteStatementOutput{
+				Id: params.Sql,
+			}, nil
+		},
+		BatchExecuteStatementFunc: func(ctx context.Context, params *redshiftdata.BatchExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.BatchExecuteStatementOutput, error) {
+			*executed = append(*executed, params.Sqls...)
+			return &redshiftdata.BatchExecuteStatementOutput{
+				Id: aws.String("batch"),
+			}, nil
+		},
+		DescribeStatementFunc: func(ctx context.Context, params *redshiftdata.DescribeStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.DescribeStatementOutput, error) {
+			if *params.Id == "batch" {
+				return &redshiftdata.DescribeStatementOutput{
+					Id:     params.Id,
+					Status: types.StatusStringFinished,
+					SubStatements: []types.SubStatementData{
+						{Id: aws.String("batch:1"), Status: types.StatementStatusStringFinished, HasResultSet: aws.Bool(false), ResultRows: 1},
+						{Id: aws.String("batch:2"), Status: types.StatementStatusStringFinished, HasResultSet: aws.Bool(false), ResultRows: 2},
+					},
+				}, nil
+			}
+			if strings.Contains(*params.Id, "broken") {
+				return &redshiftdata.DescribeStatementOutput{
+					Id:     params.Id,
+					Status: types.StatusStringFailed,
+					Error:  aws.String("syntax error"),
+				}, nil
+			}
+			return &redshiftdata.DescribeStatementOutput{
+				Id:              params.Id,
+				Status:          types.StatusStringFinished,
+				HasResultSet:    aws.Bool(strings.HasPrefix(*params.Id, "SELECT")),
+				RedshiftQueryId: 42,
+				ResultRows:      1,
+			}, nil
+		},
+		GetStatementResultFunc: func(ctx context.Context, params *redshiftdata.GetStatementResultInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.GetStatementResultOutput, error) {
+			return &redshiftdata.GetStatementResultOutput{
+				ColumnMetadata: []types.ColumnMetadata{{Name: aws.String("name"), TypeName: aws.String("varchar")}},
+				Records: [][]types.Field{
+					{&types.FieldMemberStringValue{Value: "hoge"}},
+					{&types.FieldMemberStringValue{Value: "fuga"}},
+				},
+				TotalNumRows: 2,
+			}, nil
+		},
+	}
+}
+
+func withHooks(hooks ...*Hook) func(cfg *RedshiftDataConfig) {
+	return func(cfg *RedshiftDataConfig) { cfg.WithHooks(hooks...) }
+}
+
+func TestHookRewriteAndAfterExecute(t *testing.T) {
+	var executed []string
+	type observed struct {
+		kind HookKind
+		sql  string
+		desc *redshiftdata.DescribeStatementOutput
+		err  error
+	}
+	var after []observed
+	db := openTestDB(t, newHookTestClient(&executed), withHooks(&Hook{
+		BeforeExecute: func(ctx context.Context, stmt *HookStatement) (context.Context, error) {
+			stmt.SQL = "/* app=test */ " + stmt.SQL
+			stmt.Parameters = append(stmt.Parameters, types.SqlParameter{Name: aws.String("tenant"), Value: aws.String("a")})
+			return ctx, nil
+		},
+		AfterExecute: func(ctx context.Context, stmt *HookStatement, desc *redshiftdata.DescribeStatementOutput, err error) error {
+			after = append(after, observed{kind: stmt.Kind, sql: stmt.SQL, desc: desc, err: err})
+			return err
+		},
+	}))
+	restore := requireNoErrorLog(t)
+	defer restore()
+
+	_, err := db.ExecContext(context.Background(), `DELETE FROM users WHERE id = ?`, 1)
+	require.NoError(t, err)
+	_, err = db.ExecContext(context.Background(), `SELECT broken`)
+	require.EqualError(t, err, "query failed: syntax error")
+
+	require.Equal(t, []string{
+		"/* app=test */ DELETE FROM users WHERE id = :1",
+		"/* app=test */ SELECT broken",
+	}, executed)
+	require.Len(t, after, 2)
+	require.Equal(t, HookKindExec, after[0].kind)
+	require.NoError(t, after[0].err)
+	require.EqualValues(t, 42, after[0].desc.RedshiftQueryId)
+	require.Error(t, after[1].err)
+	require.Equal(t, types.StatusStringFailed, after[1].desc.Status)
+}
+
+func TestHookReadOnlyEnforcement(t *testing.T) {
+	var executed []string
+	errReadOnly := errors.New("read only")
+	db := openTestDB(t, newHookTestClient(&executed), withHooks(&Hook{
+		BeforeExecute: func(ctx context.Context, stmt *HookStatement) (context.Context, error) {
+			if stmt.Kind != HookKindQuery {
+				return ctx, errReadOnly
+			}
+			return ctx, nil
+		},
+	}))
+	restore := requireNoErrorLog(t)
+	defer restore()
+
+	_, err := db.ExecContext(context.Background(), `DROP TABLE users`)
+	require.ErrorIs(t, err, errReadOnly)
+	tx, err := db.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	_, err = tx.ExecContext(context.Background(), `DROP TABLE users`)
+	require.NoError(t, err)
+	require.ErrorIs(t, tx.Commit(), errReadOnly)
+	require.Empty(t, executed)
+
+	var name string
+	require.NoError(t, db.QueryRowContext(context.Background(), `SELECT name FROM users`).Scan(&name))
+	require.Equal(t, []string{"SELECT name FROM users"}, executed)
+}
+
+func TestHookOnRow(t *testing.T) {
+	var executed []string
+	db := openTestDB(t, newHookTestClient(&executed), withHooks(&Hook{
+		OnRow: func(ctx context.Context, stmt *HookStatement, columns []string, row []driver.Value) error {
+			require.Equal(t, HookKindQuery, stmt.Kind)
+			require.Equal(t, []string{"name"}, columns)
+			row[0] = strings.ToUpper(row[0].(string))
+			return nil
+		},
+	}))
+	restore := requireNoErrorLog(t)
+	defer restore()
+
+	rows, err := db.QueryContext(context.Background(), `SELECT name FROM users`)
+	require.NoError(t, err)
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		require.NoError(t, rows.Scan(&name))
+		names = append(names, name)
+	}
+	require.NoError(t, rows.Err())
+	require.Equal(t, []string{"HOGE", "FUGA"}, names)
+}
+
+func TestHookCommit(t *testing.T) {
+	var executed []string
+	var committed [][]string
+	db := openTestDB(t, newHookTestClient(&executed), withHooks(&Hook{
+		BeforeExecute: func(ctx context.Context, stmt *HookStatement) (context.Context, error) {
+			if stmt.Kind == HookKindCommit {
+				for i := range stmt.Sqls {
+					stmt.Sqls[i] = "/* tx */ " + stmt.Sqls[i]
+				}
+			}
+			return ctx, nil
+		},
+		AfterExecute: func(ctx context.Context, stmt *HookStatement, desc *redshiftdata.DescribeStatementOutput, err error) error {
+			committed = append(committed, stmt.Sqls)
+			return err
+		},
+	}))
+	restore := requireNoErrorLog(t)
+	defer restore()
+
+	tx, err := db.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	r1, err := tx.ExecContext(context.Background(), `INSERT INTO foo VALUES (1)`)
+	require.NoError(t, err)
+	r2, err := tx.ExecContext(context.Background(), `INSERT INTO foo VALUES (2), (3)`)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+	require.Equal(t, []string{"/* tx */ INSERT INTO foo VALUES (1)", "/* tx */ INSERT INTO foo VALUES (2), (3)"}, executed)
+	require.Equal(t, [][]string{executed}, committed)
+	n, err := r1.RowsAffected()
+	require.NoError(t, err)
+	require.EqualValues(t, 1, n)
+	n, err = r2.RowsAffected()
+	require.NoError(t, err)
+	require.EqualValues(t, 2, n)
+
+	executed = nil
+	tx, err = db.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	r, err := tx.ExecContext(context.Background(), `INSERT INTO foo VALUES (4)`)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+	require.Equal(t, []string{"/* tx */ INSERT INTO foo VALUES (4)"}, executed)
+	n, err = r.RowsAffected()
+	require.NoError(t, err)
+	require.EqualValues(t, 1, n)
+}
+
+func TestHookAfterExecuteSwallowsError(t *testing.T) {
+	var executed []string
+	client := newHookTestClient(&executed)
+	errSubmit := errors.New("throttled")
+	execute := client.ExecuteStatementFunc
+	client.ExecuteStatementFunc = func(ctx context.Context, params *redshiftdata.ExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ExecuteStatementOutput, error) {
+		if strings.Contains(coalesce(params.Sql), "unreachable") {
+			return nil, errSubmit
+		}
+		return execute(ctx, params, optFns...)
+	}
+	client.BatchExecuteStatementFunc = func(ctx context.Context, params *redshiftdata.BatchExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.BatchExecuteStatementOutput, error) {
+		return nil, errSubmit
+	}
+	db := openTestDB(t, client, withHooks(&Hook{
+		AfterExecute: func(ctx context.Context, stmt *HookStatement, desc *redshiftdata.DescribeStatementOutput, err error) error {
+			return nil
+		},
+	}))
+	restore := requireNoErrorLog(t)
+	defer restore()
+	ctx := context.Background()
+
+	// Without a description there is no result, so the submit error is kept.
+	_, err := db.ExecContext(ctx, `DELETE FROM unreachable`)
+	require.ErrorIs(t, err, errSubmit)
+	_, err = db.QueryContext(ctx, `SELECT * FROM unreachable`)
+	require.ErrorIs(t, err, errSubmit)
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	_, err = tx.ExecContext(ctx, `INSERT INTO foo VALUES (1)`)
+	require.NoError(t, err)
+	_, err = tx.ExecContext(ctx, `INSERT INTO foo VALUES (2)`)
+	require.NoError(t, err)
+	require.ErrorIs(t, tx.Commit(), errSubmit)
+
+	// A failed statement that was described can be swallowed.
+	result, err := db.ExecContext(ctx, `SELECT broken`)
+	require.NoError(t, err)
+	n, err := result.RowsAffected()
+	require.NoError(t, err)
+	require.EqualValues(t, 0, n)
+	rows, err := db.QueryContext(ctx, `SELECT broken`)
+	require.NoError(t, err)
+	require.False(t, rows.Next())
+	require.NoError(t, rows.Err())
+	require.NoError(t, rows.Close())
+}
@@ -0,0 +1,31 @@
+// Package sqlquote quotes the identifiers and literals of the statements the driver and its subpackages build.
+package sqlquote
+
+import "strings"
+
+// Literal quotes s as a string literal. Redshift treats backslashes in literals as escapes.
+func Literal(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `''`).Replace(s) + "'"
+}
+
+func Ident(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// Idents quotes names and joins them with commas.
+func Idents(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = Ident(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// Table quotes each part of a schema qualified table name.
+func Table(table string) string {
+	parts := strings.Split(table, ".")
+	for i, part := range parts {
+		parts[i] = Ident(part)
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,15 @@
+package sqlquote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuote(t *testing.T) {
+	require.Equal(t, `'it''s a \\ test'`, Literal(`it's a \ test`))
+	require.Equal(t, `"na""me"`, Ident(`na"me`))
+	require.Equal(t, `"id", "name"`, Idents([]string{"id", "name"}))
+	require.Equal(t, `"public"."users"`, Table("public.users"))
+	require.Equal(t, `"a""b"`, Table(`a"b`))
+}
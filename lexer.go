@@ -0,0 +1,286 @@
+package redshiftdatasqldriver
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	// tokenText is anything else: keywords, identifiers, operators and whitespace.
+	tokenText tokenKind = iota
+	// tokenString is a '...' or E'...' string literal. Redshift reads backslash escapes in both.
+	tokenString
+	// tokenQuotedIdent is a "..." identifier.
+	tokenQuotedIdent
+	// tokenDollarString is a $$...$$ or $tag$...$tag$ string.
+	tokenDollarString
+	tokenNumber
+	// tokenComment is a -- line comment or a /* block comment */, which may nest.
+	tokenComment
+	// tokenCast is the :: operator.
+	tokenCast
+	// tokenPlaceholder is ?, $n, :n, :name or @name.
+	tokenPlaceholder
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	// unterminated is set on a string, quoted identifier or comment that runs to the end of sql.
+	unterminated bool
+}
+
+// lexSQL splits sql into tokens. Concatenating the token texts gives sql back.
+// Unterminated strings and comments run to the end of sql.
+func lexSQL(sql string) []token {
+	var tokens []token
+	textStart := 0
+	emit := func(start, end int, kind tokenKind) {
+		if textStart < start {
+			tokens = append(tokens, token{kind: tokenText, text: sql[textStart:start]})
+		}
+		tokens = append(tokens, token{kind: kind, text: sql[start:end]})
+		textStart = end
+	}
+	emitQuoted := func(start, end int, kind tokenKind, ok bool) {
+		emit(start, end, kind)
+		tokens[len(tokens)-1].unterminated = !ok
+	}
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == '\'':
+			end, ok := skipQuoted(sql, i, true)
+			emitQuoted(i, end, tokenString, ok)
+			i = end
+		case (c == 'E' || c == 'e') && i+1 < len(sql) && sql[i+1] == '\'':
+			end, ok := skipQuoted(sql, i+1, true)
+			emitQuoted(i, end, tokenString, ok)
+			i = end
+		case c == '"':
+			end, ok := skipQuoted(sql, i, false)
+			emitQuoted(i, end, tokenQuotedIdent, ok)
+			i = end
+		case c == '-' && i+1 < len(sql) && sql[i+1] == '-':
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				end = len(sql)
+			} else {
+				end += i
+			}
+			emit(i, end, tokenComment)
+			i = end
+		case c == '/' && i+1 < len(sql) && sql[i+1] == '*':
+			end, ok := skipBlockComment(sql, i)
+			emitQuoted(i, end, tokenComment, ok)
+			i = end
+		case c == '$':
+			if tag, ok := dollarTag(sql, i); ok {
+				end := strings.Index(sql[i+len(tag):], tag)
+				ok := end >= 0
+				if ok {
+					end += i + 2*len(tag)
+				} else {
+					end = len(sql)
+				}
+				emitQuoted(i, end, tokenDollarString, ok)
+				i = end
+				continue
+			}
+			end := skipDigits(sql, i+1)
+			if end > i+1 {
+				emit(i, end, tokenPlaceholder)
+				i = end
+				continue
+			}
+			i++
+		case c == ':':
+			switch {
+			case i+1 < len(sql) && sql[i+1] == ':':
+				emit(i, i+2, tokenCast)
+				i += 2
+			case i+1 < len(sql) && isDigit(sql[i+1]):
+				end := skipDigits(sql, i+1)
+				emit(i, end, tokenPlaceholder)
+				i = end
+			case i+1 < len(sql) && isIdentStart(sql[i+1]):
+				end := skipIdent(sql, i+1)
+				emit(i, end, tokenPlaceholder)
+				i = end
+			default:
+				i++
+			}
+		case c == '@' && i+1 < len(sql) && isIdentStart(sql[i+1]):
+			end := skipIdent(sql, i+1)
+			emit(i, end, tokenPlaceholder)
+			i = end
+		case c == '?':
+			// ?| and ?& are JSON operators, not placeholders.
+			if i+1 < len(sql) && (sql[i+1] == '|' || sql[i+1] == '&') {
+				i += 2
+				continue
+			}
+			emit(i, i+1, tokenPlaceholder)
+			i++
+		case isIdentStart(c):
+			i = skipIdent(sql, i)
+		case isDigit(c) || (c == '.' && i+1 < len(sql) && isDigit(sql[i+1])):
+			end := skipNumber(sql, i)
+			emit(i, end, tokenNumber)
+			i = end
+		default:
+			i++
+		}
+	}
+	if textStart < len(sql) {
+		tokens = append(tokens, token{kind: tokenText, text: sql[textStart:]})
+	}
+	return tokens
+}
+
+// splitStatements splits sql on the semicolons outside strings, quoted identifiers and comments.
+// The statements are trimmed, and those with nothing but comments and whitespace are dropped.
+func splitStatements(sql string) []string {
+	var stmts []string
+	var start, offset int
+	var hasCode bool
+	flush := func(end int) {
+		if hasCode {
+			stmts = append(stmts, strings.TrimSpace(sql[start:end]))
+		}
+		start = end + 1
+		hasCode = false
+	}
+	for _, tok := range lexSQL(sql) {
+		switch tok.kind {
+		case tokenText:
+			for i := 0; i < len(tok.text); i++ {
+				switch c := tok.text[i]; {
+				case c == ';':
+					flush(offset + i)
+				case !unicode.IsSpace(rune(c)):
+					hasCode = true
+				}
+			}
+		case tokenComment:
+		default:
+			hasCode = true
+		}
+		offset += len(tok.text)
+	}
+	flush(len(sql))
+	return stmts
+}
+
+// hasComment reports whether sql has a comment outside string literals and quoted identifiers.
+func hasComment(sql string) bool {
+	for _, tok := range lexSQL(sql) {
+		if tok.kind == tokenComment {
+			return true
+		}
+	}
+	return false
+}
+
+// skipQuoted returns the index just after the quoted section starting at sql[start],
+// and false if the section is not closed before the end of sql.
+// Doubled quotes are part of the section, and so are backslash escapes when escapes is true.
+func skipQuoted(sql string, start int, escapes bool) (int, bool) {
+	quote := sql[start]
+	for i := start + 1; i < len(sql); i++ {
+		switch {
+		case escapes && sql[i] == '\\':
+			i++
+		case sql[i] == quote:
+			if i+1 < len(sql) && sql[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1, true
+		}
+	}
+	return len(sql), false
+}
+
+// skipBlockComment returns the index just after the block comment starting at sql[start],
+// and false if the comment is not closed before the end of sql.
+func skipBlockComment(sql string, start int) (int, bool) {
+	depth := 0
+	for i := start; i+1 < len(sql); i++ {
+		switch {
+		case sql[i] == '/' && sql[i+1] == '*':
+			depth++
+			i++
+		case sql[i] == '*' && sql[i+1] == '/':
+			depth--
+			i++
+			if depth == 0 {
+				return i + 1, true
+			}
+		}
+	}
+	return len(sql), false
+}
+
+// dollarTag reports the $tag$ opening a dollar-quoted string at sql[start].
+func dollarTag(sql string, start int) (string, bool) {
+	for i := start + 1; i < len(sql); i++ {
+		switch {
+		case sql[i] == '$':
+			return sql[start : i+1], true
+		case isDigit(sql[i]) && i == start+1:
+			return "", false
+		case !isIdentByte(sql[i]):
+			return "", false
+		}
+	}
+	return "", false
+}
+
+func skipDigits(sql string, start int) int {
+	i := start
+	for i < len(sql) && isDigit(sql[i]) {
+		i++
+	}
+	return i
+}
+
+func skipIdent(sql string, start int) int {
+	i := start
+	for i < len(sql) && (isIdentByte(sql[i]) || sql[i] == '$') {
+		i++
+	}
+	return i
+}
+
+func skipNumber(sql string, start int) int {
+	i := start
+	for i < len(sql) && (isDigit(sql[i]) || sql[i] == '.') {
+		i++
+	}
+	if i < len(sql) && (sql[i] == 'e' || sql[i] == 'E') {
+		j := i + 1
+		if j < len(sql) && (sql[j] == '+' || sql[j] == '-') {
+			j++
+		}
+		if j < len(sql) && isDigit(sql[j]) {
+			i = skipDigits(sql, j)
+		}
+	}
+	return i
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c >= utf8.RuneSelf || unicode.IsLetter(rune(c))
+}
+
+func isIdentByte(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
@@ -0,0 +1,100 @@
+package redshiftdatasqldriver
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLexSQL(t *testing.T) {
+	tokens := lexSQL("SELECT e'\\'', $tag$ $1 $tag$, col1::int, 1.5e3, :name, \"q\"\"\" -- c\n/* a /* b */ c */?")
+	var got []token
+	for _, tok := range tokens {
+		if tok.kind != tokenText {
+			got = append(got, tok)
+		}
+	}
+	require.Equal(t, []token{
+		{kind: tokenString, text: `e'\''`},
+		{kind: tokenDollarString, text: `$tag$ $1 $tag$`},
+		{kind: tokenCast, text: `::`},
+		{kind: tokenNumber, text: `1.5e3`},
+		{kind: tokenPlaceholder, text: `:name`},
+		{kind: tokenQuotedIdent, text: `"q"""`},
+		{kind: tokenComment, text: "-- c"},
+		{kind: tokenComment, text: `/* a /* b */ c */`},
+		{kind: tokenPlaceholder, text: `?`},
+	}, got)
+
+	require.Equal(t, []string{`INSERT INTO t VALUES ('a\'; DROP')`, `SELECT 1`}, splitStatements(`INSERT INTO t VALUES ('a\'; DROP'); SELECT 1`))
+}
+
+func FuzzLexSQL(f *testing.F) {
+	for _, seed := range []string{
+		`SELECT * FROM users WHERE id = ? AND name = $2`,
+		`SELECT 'it''s', E'\'', 'a\'b ?', "a""b", $$ ? $$, $x$ $1 $x$ FROM t`,
+		`INSERT INTO t VALUES ('a\'; DROP'); SELECT 1`,
+		"SELECT 1 -- ?\n/* /* ? */ */ :name::int",
+		`SELECT j ?| array['a'] FROM t WHERE x = :1`,
+		`'unterminated`,
+		`/* unterminated`,
+		`$tag$ unterminated`,
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, sql string) {
+		var b strings.Builder
+		var placeholders int
+		for _, tok := range lexSQL(sql) {
+			if tok.text == "" {
+				t.Fatalf("empty token in %q", sql)
+			}
+			switch tok.kind {
+			case tokenPlaceholder:
+				placeholders++
+			case tokenString:
+				if !strings.HasPrefix(tok.text, "'") && !strings.HasPrefix(strings.ToUpper(tok.text), "E'") {
+					t.Fatalf("string token %q in %q", tok.text, sql)
+				}
+			case tokenComment:
+				if !strings.HasPrefix(tok.text, "--") && !strings.HasPrefix(tok.text, "/*") {
+					t.Fatalf("comment token %q in %q", tok.text, sql)
+				}
+			}
+			// A token lexed on its own is the same token, so nothing after it changes its end.
+			if tok.kind != tokenText {
+				if again := lexSQL(tok.text); len(again) != 1 || again[0] != tok {
+					t.Fatalf("token %q of %q lexed alone as %v", tok.text, sql, again)
+				}
+			}
+			b.WriteString(tok.text)
+		}
+		if b.String() != sql {
+			t.Fatalf("tokens of %q joined to %q", sql, b.String())
+		}
+		q := parseQuery(sql)
+		if placeholders != len(q.placeholders) {
+			t.Fatalf("parse of %q found %d placeholders, want %d", sql, len(q.placeholders), placeholders)
+		}
+		if placeholders == 0 && q.rewritten != sql {
+			t.Fatalf("rewrite without placeholders changed %q", sql)
+		}
+		if rewritten, _, err := q.bind(nil); err != nil || rewritten != sql {
+			t.Fatalf("bind without args changed %q", sql)
+		}
+		for _, stmt := range splitStatements(sql) {
+			for _, tok := range lexSQL(stmt) {
+				if tok.kind == tokenText && strings.Contains(tok.text, ";") {
+					t.Fatalf("statement %q split from %q has a semicolon outside literals", stmt, sql)
+				}
+			}
+		}
+		redacted := redactSQL(sql)
+		for _, tok := range lexSQL(redacted) {
+			if tok.kind == tokenString || tok.kind == tokenDollarString || tok.kind == tokenNumber {
+				t.Fatalf("redaction of %q left literal %q", sql, tok.text)
+			}
+		}
+	})
+}
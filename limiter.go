@@ -0,0 +1,44 @@
+package redshiftdatasqldriver
+
+import "context"
+
+// StatementLimiter bounds the number of active statements.
+// Share one between connectors with WithStatementLimiter to bound all of them together,
+// for example to stay under the Data API quota of an account.
+type StatementLimiter struct {
+	sem chan struct{}
+}
+
+// NewStatementLimiter returns a limiter that allows n active statements.
+func NewStatementLimiter(n int) *StatementLimiter {
+	if n <= 0 {
+		n = 1
+	}
+	return &StatementLimiter{
+		sem: make(chan struct{}, n),
+	}
+}
+
+// acquire waits for a free slot until ctx is done. The returned func releases it.
+func (l *StatementLimiter) acquire(ctx context.Context) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	select {
+	case l.sem <- struct{}{}:
+		return func() { <-l.sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// statementLimiter returns the shared limiter, or a new one for MaxActiveStatements.
+func (cfg *RedshiftDataConfig) statementLimiter() *StatementLimiter {
+	if cfg.StatementLimiter != nil {
+		return cfg.StatementLimiter
+	}
+	if cfg.MaxActiveStatements > 0 {
+		return NewStatementLimiter(cfg.MaxActiveStatements)
+	}
+	return nil
+}
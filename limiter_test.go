@@ -0,0 +1,131 @@
+package redshiftdatasqldriver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
+	"github.com/stretchr/testify/require"
+)
+
+type activeStatementsClient struct {
+	*mockRedshiftDataClient
+	mu        sync.Mutex
+	active    int
+	maxActive int
+}
+
+func newActiveStatementsClient(latency time.Duration) *activeStatementsClient {
+	c := &activeStatementsClient{}
+	submittedAt := map[string]time.Time{}
+	var seq int
+	c.mockRedshiftDataClient = &mockRedshiftDataClient{
+		ExecuteStatementFunc: func(ctx context.Context, params *redshiftdata.ExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ExecuteStatementOutput, error) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			seq++
+			id := fmt.Sprintf("statement-%d", seq)
+			submittedAt[id] = time.Now()
+			c.active++
+			if c.active > c.maxActive {
+				c.maxActive = c.active
+			}
+			return &redshiftdata.ExecuteStatementOutput{Id: aws.String(id)}, nil
+		},
+		DescribeStatementFunc: func(ctx context.Context, params *redshiftdata.DescribeStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.DescribeStatementOutput, error) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			status := types.StatusStringStarted
+			if time.Since(submittedAt[*params.Id]) >= latency {
+				status = types.StatusStringFinished
+				c.active--
+			}
+			return &redshiftdata.DescribeStatementOutput{
+				Id:           params.Id,
+				Status:       status,
+				HasResultSet: aws.Bool(false),
+			}, nil
+		},
+		CancelStatementFunc: func(ctx context.Context, params *redshiftdata.CancelStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.CancelStatementOutput, error) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.active--
+			return &redshiftdata.CancelStatementOutput{Status: aws.Bool(true)}, nil
+		},
+	}
+	return c
+}
+
+func TestStatementLimiter(t *testing.T) {
+	client := newActiveStatementsClient(5 * time.Millisecond)
+	limiter := NewStatementLimiter(2)
+	open := func() *sql.DB {
+		cfg := (&RedshiftDataConfig{
+			WorkgroupName: aws.String("default"),
+			Database:      aws.String("dev"),
+			Polling:       time.Millisecond,
+		}).WithRedshiftDataClientConstructor(func(ctx context.Context, cfg *RedshiftDataConfig) (RedshiftDataClient, error) {
+			return client, nil
+		}).WithStatementLimiter(limiter)
+		db := sql.OpenDB(NewConnector(cfg))
+		t.Cleanup(func() { db.Close() })
+		return db
+	}
+	dbs := []*sql.DB{open(), open()}
+	restore := requireNoErrorLog(t)
+	defer restore()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(db *sql.DB) {
+			defer wg.Done()
+			_, err := db.ExecContext(context.Background(), `DELETE FROM sessions`)
+			require.NoError(t, err)
+		}(dbs[i%2])
+	}
+	wg.Wait()
+	require.Equal(t, 2, client.maxActive)
+	require.Equal(t, 0, client.active)
+}
+
+func TestMaxActiveStatements(t *testing.T) {
+	client := newActiveStatementsClient(time.Hour)
+	cfg, err := ParseDSN("workgroup(default)/dev?max_active_statements=1&polling=1ms")
+	require.NoError(t, err)
+	require.Equal(t, 1, cfg.MaxActiveStatements)
+	cfg = cfg.WithRedshiftDataClientConstructor(func(ctx context.Context, cfg *RedshiftDataConfig) (RedshiftDataClient, error) {
+		return client, nil
+	})
+	db := sql.OpenDB(NewConnector(cfg))
+	defer db.Close()
+	restore := requireNoErrorLog(t)
+	defer restore()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		db.ExecContext(ctx, `VACUUM`)
+	}()
+	require.Eventually(t, func() bool {
+		client.mu.Lock()
+		defer client.mu.Unlock()
+		return client.active == 1
+	}, time.Second, time.Millisecond)
+
+	wctx, wcancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer wcancel()
+	_, err = db.ExecContext(wctx, `DELETE FROM sessions`)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, 1, client.maxActive)
+
+	cancel()
+	<-done
+}
@@ -1,10 +1,17 @@
 package redshiftdatasqldriver
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"io"
 	"log"
+	"log/slog"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Logger interface {
@@ -15,6 +22,7 @@ type Logger interface {
 
 var errLogger = Logger(log.New(os.Stderr, "[redshift-data][error]", log.Ldate|log.Ltime|log.Lshortfile))
 var debugLogger = Logger(log.New(io.Discard, "[redshift-data][debug]", log.Ldate|log.Ltime|log.Lshortfile))
+var slogHandler slog.Handler
 
 func SetLogger(l Logger) error {
 	if l == nil {
@@ -31,3 +39,93 @@ func SetDebugLogger(l Logger) error {
 	debugLogger = l
 	return nil
 }
+
+// SetSlogHandler routes every log event to h as a structured record instead of the
+// Printf-style loggers. Passing nil restores the Printf-style loggers.
+func SetSlogHandler(h slog.Handler) {
+	slogHandler = h
+}
+
+func (cfg *RedshiftDataConfig) errLogger() Logger {
+	if cfg != nil && cfg.Logger != nil {
+		return cfg.Logger
+	}
+	return errLogger
+}
+
+func (cfg *RedshiftDataConfig) debugLogger() Logger {
+	if cfg != nil && cfg.DebugLogger != nil {
+		return cfg.DebugLogger
+	}
+	return debugLogger
+}
+
+func (cfg *RedshiftDataConfig) slogHandler() slog.Handler {
+	if cfg != nil && cfg.SlogHandler != nil {
+		return cfg.SlogHandler
+	}
+	return slogHandler
+}
+
+func (cfg *RedshiftDataConfig) logDebug(ctx context.Context, msg string, attrs ...slog.Attr) {
+	cfg.log(ctx, slog.LevelDebug, msg, attrs)
+}
+
+func (cfg *RedshiftDataConfig) logError(ctx context.Context, msg string, attrs ...slog.Attr) {
+	cfg.log(ctx, slog.LevelError, msg, attrs)
+}
+
+func (cfg *RedshiftDataConfig) log(ctx context.Context, level slog.Level, msg string, attrs []slog.Attr) {
+	if h := cfg.slogHandler(); h != nil {
+		if !h.Enabled(ctx, level) {
+			return
+		}
+		record := slog.NewRecord(time.Now(), level, msg, 0)
+		record.AddAttrs(attrs...)
+		h.Handle(ctx, record)
+		return
+	}
+	l := cfg.debugLogger()
+	if level >= slog.LevelError {
+		l = cfg.errLogger()
+	}
+	l.Printf("%s", formatLogLine(msg, attrs))
+}
+
+// formatLogLine renders an event for the Printf-style loggers as "[statement_id] msg: key=value ...".
+func formatLogLine(msg string, attrs []slog.Attr) string {
+	var b strings.Builder
+	var rest []slog.Attr
+	for _, attr := range attrs {
+		if attr.Key == "statement_id" {
+			b.WriteString("[" + attr.Value.String() + "] ")
+			continue
+		}
+		rest = append(rest, attr)
+	}
+	b.WriteString(msg)
+	for i, attr := range rest {
+		if i == 0 {
+			b.WriteString(":")
+		}
+		value := attr.Value.String()
+		if strings.ContainsAny(value, " \t\r\n\"=") {
+			value = strconv.Quote(value)
+		}
+		b.WriteString(" " + attr.Key + "=" + value)
+	}
+	return b.String()
+}
+
+func statementIDAttr(id *string) slog.Attr {
+	return slog.String("statement_id", coalesce(id))
+}
+
+func sqlHashAttr(sql string) slog.Attr {
+	sum := sha256.Sum256([]byte(sql))
+	return slog.String("sql_hash", hex.EncodeToString(sum[:8]))
+}
+
+func elapsedAttr(start time.Time) slog.Attr {
+	return slog.Duration("elapsed", time.Since(start))
+}
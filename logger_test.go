@@ -1,9 +1,18 @@
 package redshiftdatasqldriver
 
 import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"log/slog"
 	"strings"
 	"testing"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
 	"github.com/stretchr/testify/require"
 )
 
@@ -23,3 +32,100 @@ func requireNoErrorLog(t *testing.T) func() {
 		}
 	}
 }
+
+func TestPerConfigLogger(t *testing.T) {
+	client := &mockRedshiftDataClient{
+		ExecuteStatementFunc: func(ctx context.Context, params *redshiftdata.ExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ExecuteStatementOutput, error) {
+			return &redshiftdata.ExecuteStatementOutput{
+				Id: aws.String("tenant-a-statement"),
+			}, nil
+		},
+		DescribeStatementFunc: func(ctx context.Context, params *redshiftdata.DescribeStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.DescribeStatementOutput, error) {
+			return &redshiftdata.DescribeStatementOutput{
+				Id:           params.Id,
+				Status:       types.StatusStringFinished,
+				HasResultSet: aws.Bool(false),
+			}, nil
+		},
+	}
+	var tenantDebug strings.Builder
+	cfg := (&RedshiftDataConfig{
+		WorkgroupName: aws.String("tenant-a"),
+		Database:      aws.String("dev"),
+	}).WithRedshiftDataClientConstructor(func(ctx context.Context, cfg *RedshiftDataConfig) (RedshiftDataClient, error) {
+		return client, nil
+	}).WithDebugLogger(log.New(&tenantDebug, "[tenant-a]", 0))
+	restore := requireNoErrorLog(t)
+	defer restore()
+	var globalDebug strings.Builder
+	debugOrig := debugLogger.Writer()
+	debugLogger.SetOutput(&globalDebug)
+	defer debugLogger.SetOutput(debugOrig)
+
+	db := sql.OpenDB(NewConnector(cfg))
+	defer db.Close()
+	_, err := db.ExecContext(context.Background(), `DELETE FROM users`)
+	require.NoError(t, err)
+	require.Contains(t, tenantDebug.String(), "[tenant-a][tenant-a-statement] success execute statement")
+	require.Empty(t, globalDebug.String())
+}
+
+func TestSlogHandler(t *testing.T) {
+	client := &mockRedshiftDataClient{
+		ExecuteStatementFunc: func(ctx context.Context, params *redshiftdata.ExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ExecuteStatementOutput, error) {
+			return &redshiftdata.ExecuteStatementOutput{
+				Id: aws.String("dummy"),
+			}, nil
+		},
+		DescribeStatementFunc: func(ctx context.Context, params *redshiftdata.DescribeStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.DescribeStatementOutput, error) {
+			return &redshiftdata.DescribeStatementOutput{
+				Id:              params.Id,
+				Status:          types.StatusStringFinished,
+				HasResultSet:    aws.Bool(false),
+				RedshiftQueryId: 1234,
+				ResultRows:      3,
+			}, nil
+		},
+	}
+	newDB := func(h slog.Handler) *sql.DB {
+		cfg := (&RedshiftDataConfig{
+			WorkgroupName: aws.String("default"),
+			Database:      aws.String("dev"),
+		}).WithRedshiftDataClientConstructor(func(ctx context.Context, cfg *RedshiftDataConfig) (RedshiftDataClient, error) {
+			return client, nil
+		}).WithSlogHandler(h)
+		return sql.OpenDB(NewConnector(cfg))
+	}
+	restore := requireNoErrorLog(t)
+	defer restore()
+
+	var buf bytes.Buffer
+	db := newDB(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	defer db.Close()
+	_, err := db.ExecContext(context.Background(), `DELETE FROM users`)
+	require.NoError(t, err)
+	events := map[string]map[string]any{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var event map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &event))
+		events[event["msg"].(string)] = event
+	}
+	require.Contains(t, events, "submit statement")
+	require.Contains(t, events, "describe statement")
+	finished, ok := events["statement finished"]
+	require.True(t, ok)
+	require.Equal(t, "DEBUG", finished["level"])
+	require.Equal(t, "dummy", finished["statement_id"])
+	require.Equal(t, float64(1234), finished["query_id"])
+	require.Equal(t, "FINISHED", finished["status"])
+	require.Equal(t, float64(3), finished["result_rows"])
+	require.Contains(t, finished, "elapsed")
+	require.Equal(t, events["submit statement"]["sql_hash"], finished["sql_hash"])
+
+	buf.Reset()
+	db = newDB(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelError}))
+	defer db.Close()
+	_, err = db.ExecContext(context.Background(), `DELETE FROM users`)
+	require.NoError(t, err)
+	require.Empty(t, buf.String())
+}
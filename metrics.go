@@ -0,0 +1,183 @@
+package redshiftdatasqldriver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
+)
+
+// MetricsRecorder receives measurements about Data API usage.
+// The redshiftdatametrics package provides an OpenTelemetry implementation.
+type MetricsRecorder interface {
+	// RecordAPICall is called after every Data API call with the operation name, such as "DescribeStatement".
+	RecordAPICall(ctx context.Context, operation string, err error)
+	// RecordStatement is called once per statement after it reaches a final status or is cancelled.
+	RecordStatement(ctx context.Context, m StatementMetrics)
+}
+
+// StatementMetrics describes one statement, taken from the DescribeStatement outputs seen while waiting.
+type StatementMetrics struct {
+	StatementID string
+	QueryID     int64
+	Status      types.StatusString
+	// Polls is the number of DescribeStatement calls made for the statement.
+	Polls int
+	// QueueTime is the time spent before the statement was STARTED.
+	QueueTime time.Duration
+	// ExecutionTime is the time spent from STARTED until the final status.
+	ExecutionTime time.Duration
+	ResultRows    int64
+	ResultSize    int64
+}
+
+type statementStats struct {
+	polls     int
+	startedAt time.Time
+}
+
+func (s *statementStats) observe(desc *redshiftdata.DescribeStatementOutput) {
+	s.polls++
+	if desc.Status == types.StatusStringStarted && s.startedAt.IsZero() {
+		s.startedAt = time.Now()
+	}
+}
+
+func (conn *redshiftDataConn) recordStatement(ctx context.Context, id *string, desc *redshiftdata.DescribeStatementOutput, status types.StatusString, queryStart time.Time, stats *statementStats) {
+	recorder := conn.cfg.MetricsRecorder
+	if recorder == nil {
+		return
+	}
+	now := time.Now()
+	total := now.Sub(queryStart)
+	var queueTime, executionTime time.Duration
+	switch {
+	case desc.Duration > 0:
+		executionTime = time.Duration(desc.Duration)
+	case !stats.startedAt.IsZero():
+		executionTime = now.Sub(stats.startedAt)
+	}
+	if !stats.startedAt.IsZero() {
+		queueTime = stats.startedAt.Sub(queryStart)
+	} else if total > executionTime {
+		queueTime = total - executionTime
+	}
+	recorder.RecordStatement(ctx, StatementMetrics{
+		StatementID:   coalesce(id),
+		QueryID:       desc.RedshiftQueryId,
+		Status:        status,
+		Polls:         stats.polls,
+		QueueTime:     queueTime,
+		ExecutionTime: executionTime,
+		ResultRows:    desc.ResultRows,
+		ResultSize:    desc.ResultSize,
+	})
+}
+
+// metricsClient reports every call made through client to recorder.
+type metricsClient struct {
+	client   RedshiftDataClient
+	recorder MetricsRecorder
+}
+
+func newMetricsClient(client RedshiftDataClient, recorder MetricsRecorder) RedshiftDataClient {
+	if recorder == nil {
+		return client
+	}
+	return &metricsClient{
+		client:   client,
+		recorder: recorder,
+	}
+}
+
+func (c *metricsClient) ExecuteStatement(ctx context.Context, params *redshiftdata.ExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ExecuteStatementOutput, error) {
+	output, err := c.client.ExecuteStatement(ctx, params, optFns...)
+	c.recorder.RecordAPICall(ctx, "ExecuteStatement", err)
+	return output, err
+}
+
+func (c *metricsClient) DescribeStatement(ctx context.Context, params *redshiftdata.DescribeStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.DescribeStatementOutput, error) {
+	output, err := c.client.DescribeStatement(ctx, params, optFns...)
+	c.recorder.RecordAPICall(ctx, "DescribeStatement", err)
+	return output, err
+}
+
+func (c *metricsClient) CancelStatement(ctx context.Context, params *redshiftdata.CancelStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.CancelStatementOutput, error) {
+	output, err := c.client.CancelStatement(ctx, params, optFns...)
+	c.recorder.RecordAPICall(ctx, "CancelStatement", err)
+	return output, err
+}
+
+func (c *metricsClient) BatchExecuteStatement(ctx context.Context, params *redshiftdata.BatchExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.BatchExecuteStatementOutput, error) {
+	output, err := c.client.BatchExecuteStatement(ctx, params, optFns...)
+	c.recorder.RecordAPICall(ctx, "BatchExecuteStatement", err)
+	return output, err
+}
+
+func (c *metricsClient) GetStatementResult(ctx context.Context, params *redshiftdata.GetStatementResultInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.GetStatementResultOutput, error) {
+	output, err := c.client.GetStatementResult(ctx, params, optFns...)
+	c.recorder.RecordAPICall(ctx, "GetStatementResult", err)
+	return output, err
+}
+
+func (c *metricsClient) GetStatementResultV2(ctx context.Context, params *redshiftdata.GetStatementResultV2Input, optFns ...func(*redshiftdata.Options)) (*redshiftdata.GetStatementResultV2Output, error) {
+	client, ok := c.client.(redshiftdata.GetStatementResultV2APIClient)
+	if !ok {
+		return nil, fmt.Errorf("csv result format: GetStatementResultV2 %w", ErrNotSupported)
+	}
+	output, err := client.GetStatementResultV2(ctx, params, optFns...)
+	c.recorder.RecordAPICall(ctx, "GetStatementResultV2", err)
+	return output, err
+}
+
+func (c *metricsClient) ListDatabases(ctx context.Context, params *redshiftdata.ListDatabasesInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ListDatabasesOutput, error) {
+	client, ok := c.client.(redshiftdata.ListDatabasesAPIClient)
+	if !ok {
+		return nil, fmt.Errorf("catalog: ListDatabases %w", ErrNotSupported)
+	}
+	output, err := client.ListDatabases(ctx, params, optFns...)
+	c.recorder.RecordAPICall(ctx, "ListDatabases", err)
+	return output, err
+}
+
+func (c *metricsClient) ListSchemas(ctx context.Context, params *redshiftdata.ListSchemasInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ListSchemasOutput, error) {
+	client, ok := c.client.(redshiftdata.ListSchemasAPIClient)
+	if !ok {
+		return nil, fmt.Errorf("catalog: ListSchemas %w", ErrNotSupported)
+	}
+	output, err := client.ListSchemas(ctx, params, optFns...)
+	c.recorder.RecordAPICall(ctx, "ListSchemas", err)
+	return output, err
+}
+
+func (c *metricsClient) ListTables(ctx context.Context, params *redshiftdata.ListTablesInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ListTablesOutput, error) {
+	client, ok := c.client.(redshiftdata.ListTablesAPIClient)
+	if !ok {
+		return nil, fmt.Errorf("catalog: ListTables %w", ErrNotSupported)
+	}
+	output, err := client.ListTables(ctx, params, optFns...)
+	c.recorder.RecordAPICall(ctx, "ListTables", err)
+	return output, err
+}
+
+func (c *metricsClient) DescribeTable(ctx context.Context, params *redshiftdata.DescribeTableInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.DescribeTableOutput, error) {
+	client, ok := c.client.(redshiftdata.DescribeTableAPIClient)
+	if !ok {
+		return nil, fmt.Errorf("catalog: DescribeTable %w", ErrNotSupported)
+	}
+	output, err := client.DescribeTable(ctx, params, optFns...)
+	c.recorder.RecordAPICall(ctx, "DescribeTable", err)
+	return output, err
+}
+
+func (c *metricsClient) ListStatements(ctx context.Context, params *redshiftdata.ListStatementsInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ListStatementsOutput, error) {
+	client, ok := c.client.(redshiftdata.ListStatementsAPIClient)
+	if !ok {
+		return nil, fmt.Errorf("statement history: ListStatements %w", ErrNotSupported)
+	}
+	output, err := client.ListStatements(ctx, params, optFns...)
+	c.recorder.RecordAPICall(ctx, "ListStatements", err)
+	return output, err
+}
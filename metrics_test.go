@@ -0,0 +1,80 @@
+package redshiftdatasqldriver
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
+	"github.com/stretchr/testify/require"
+)
+
+type testMetricsRecorder struct {
+	mu         sync.Mutex
+	apiCalls   map[string]int
+	statements []StatementMetrics
+}
+
+func (r *testMetricsRecorder) RecordAPICall(ctx context.Context, operation string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.apiCalls == nil {
+		r.apiCalls = map[string]int{}
+	}
+	r.apiCalls[operation]++
+}
+
+func (r *testMetricsRecorder) RecordStatement(ctx context.Context, m StatementMetrics) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statements = append(r.statements, m)
+}
+
+func TestMetricsRecorder(t *testing.T) {
+	statuses := []types.StatusString{types.StatusStringSubmitted, types.StatusStringPicked, types.StatusStringStarted, types.StatusStringFinished}
+	var polls int
+	client := &mockRedshiftDataClient{
+		ExecuteStatementFunc: func(ctx context.Context, params *redshiftdata.ExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ExecuteStatementOutput, error) {
+			polls = 0
+			return &redshiftdata.ExecuteStatementOutput{
+				Id: aws.String("dummy"),
+			}, nil
+		},
+		DescribeStatementFunc: func(ctx context.Context, params *redshiftdata.DescribeStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.DescribeStatementOutput, error) {
+			status := statuses[polls]
+			polls++
+			output := &redshiftdata.DescribeStatementOutput{
+				Id:              params.Id,
+				Status:          status,
+				HasResultSet:    aws.Bool(false),
+				RedshiftQueryId: 42,
+			}
+			if status == types.StatusStringFinished {
+				output.ResultRows = 10
+				output.ResultSize = 100
+			}
+			return output, nil
+		},
+	}
+	recorder := &testMetricsRecorder{}
+	db := openTestDB(t, client, func(cfg *RedshiftDataConfig) { cfg.WithMetricsRecorder(recorder) })
+	restore := requireNoErrorLog(t)
+	defer restore()
+
+	_, err := db.ExecContext(context.Background(), `INSERT INTO users SELECT * FROM staging_users`)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"ExecuteStatement": 1, "DescribeStatement": 4}, recorder.apiCalls)
+	require.Len(t, recorder.statements, 1)
+	m := recorder.statements[0]
+	require.Equal(t, "dummy", m.StatementID)
+	require.Equal(t, int64(42), m.QueryID)
+	require.Equal(t, types.StatusStringFinished, m.Status)
+	require.Equal(t, 4, m.Polls)
+	require.Equal(t, int64(10), m.ResultRows)
+	require.Equal(t, int64(100), m.ResultSize)
+	require.Greater(t, m.QueueTime, time.Duration(0))
+	require.Greater(t, m.ExecutionTime, time.Duration(0))
+}
@@ -2,11 +2,37 @@ package redshiftdatasqldriver
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
 )
 
+// openTestDB opens a DB on the default workgroup and the dev database whose connections use client.
+// configure adjusts the config before it is opened.
+func openTestDB(t *testing.T, client RedshiftDataClient, configure ...func(cfg *RedshiftDataConfig)) *sql.DB {
+	t.Helper()
+	cfg := (&RedshiftDataConfig{
+		WorkgroupName: aws.String("default"),
+		Database:      aws.String("dev"),
+	}).WithRedshiftDataClientConstructor(func(ctx context.Context, cfg *RedshiftDataConfig) (RedshiftDataClient, error) {
+		return client, nil
+	})
+	for _, f := range configure {
+		f(cfg)
+	}
+	db := sql.OpenDB(NewConnector(cfg))
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func withFastPolling(cfg *RedshiftDataConfig) {
+	cfg.Polling = time.Millisecond
+}
+
 type mockRedshiftDataClient struct {
 	ExecuteStatementFunc      func(ctx context.Context, params *redshiftdata.ExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ExecuteStatementOutput, error)
 	DescribeStatementFunc     func(ctx context.Context, params *redshiftdata.DescribeStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.DescribeStatementOutput, error)
@@ -30,21 +56,21 @@ func (m *mockRedshiftDataClient) DescribeStatement(ctx context.Context, params *
 }
 
 func (m *mockRedshiftDataClient) CancelStatement(ctx context.Context, params *redshiftdata.CancelStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.CancelStatementOutput, error) {
-	if m.DescribeStatementFunc == nil {
+	if m.CancelStatementFunc == nil {
 		return nil, errors.New("unexpected call CancelStatement")
 	}
 	return m.CancelStatementFunc(ctx, params)
 }
 
 func (m *mockRedshiftDataClient) GetStatementResult(ctx context.Context, params *redshiftdata.GetStatementResultInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.GetStatementResultOutput, error) {
-	if m.DescribeStatementFunc == nil {
+	if m.GetStatementResultFunc == nil {
 		return nil, errors.New("unexpected call GetStatementResult")
 	}
 	return m.GetStatementResultFunc(ctx, params)
 }
 
 func (m *mockRedshiftDataClient) BatchExecuteStatement(ctx context.Context, params *redshiftdata.BatchExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.BatchExecuteStatementOutput, error) {
-	if m.DescribeStatementFunc == nil {
+	if m.BatchExecuteStatementFunc == nil {
 		return nil, errors.New("unexpected call BatchExecuteStatement")
 	}
 	return m.BatchExecuteStatementFunc(ctx, params)
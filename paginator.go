@@ -0,0 +1,135 @@
+package redshiftdatasqldriver
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
+)
+
+// resultPaginator pages through a statement result as GetStatementResult outputs.
+type resultPaginator interface {
+	HasMorePages() bool
+	NextPage(ctx context.Context, optFns ...func(*redshiftdata.Options)) (*redshiftdata.GetStatementResultOutput, error)
+}
+
+func (conn *redshiftDataConn) newResultPaginator(id *string, format types.ResultFormatString) resultPaginator {
+	if format == types.ResultFormatStringCsv {
+		return &csvResultPaginator{
+			client: conn.client,
+			id:     id,
+		}
+	}
+	return redshiftdata.NewGetStatementResultPaginator(conn.client, &redshiftdata.GetStatementResultInput{
+		Id: id,
+	})
+}
+
+// csvResultPaginator reads CSV results with GetStatementResultV2 and converts the records
+// to fields, typed by the column metadata.
+//
+// The CSV of the Data API writes NULL and the empty string alike, as an empty value.
+// An empty value is read as the empty string in a string column the metadata marks as not nullable, and as NULL otherwise.
+type csvResultPaginator struct {
+	client    RedshiftDataClient
+	id        *string
+	p         *redshiftdata.GetStatementResultV2Paginator
+	firstPage bool
+}
+
+func (p *csvResultPaginator) HasMorePages() bool {
+	return p.p == nil || p.p.HasMorePages()
+}
+
+func (p *csvResultPaginator) NextPage(ctx context.Context, optFns ...func(*redshiftdata.Options)) (*redshiftdata.GetStatementResultOutput, error) {
+	if p.p == nil {
+		client, ok := p.client.(redshiftdata.GetStatementResultV2APIClient)
+		if !ok {
+			return nil, fmt.Errorf("csv result format: GetStatementResultV2 %w", ErrNotSupported)
+		}
+		p.p = redshiftdata.NewGetStatementResultV2Paginator(client, &redshiftdata.GetStatementResultV2Input{
+			Id: p.id,
+		})
+		p.firstPage = true
+	}
+	output, err := p.p.NextPage(ctx, optFns...)
+	if err != nil {
+		return nil, err
+	}
+	firstPage := p.firstPage
+	p.firstPage = false
+	records := make([][]types.Field, 0, len(output.Records))
+	for _, r := range output.Records {
+		csvRecords, ok := r.(*types.QueryRecordsMemberCSVRecords)
+		if !ok {
+			continue
+		}
+		rows, err := csv.NewReader(strings.NewReader(csvRecords.Value)).ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("parse csv records: %w", err)
+		}
+		// The Data API starts the CSV of the first page, and only that one, with the column names.
+		if firstPage && len(rows) > 0 {
+			rows = rows[1:]
+		}
+		firstPage = false
+		for _, row := range rows {
+			fields := make([]types.Field, len(row))
+			for i, value := range row {
+				var meta types.ColumnMetadata
+				if i < len(output.ColumnMetadata) {
+					meta = output.ColumnMetadata[i]
+				}
+				fields[i] = csvField(meta, value)
+			}
+			records = append(records, fields)
+		}
+	}
+	return &redshiftdata.GetStatementResultOutput{
+		ColumnMetadata: output.ColumnMetadata,
+		Records:        records,
+		NextToken:      output.NextToken,
+		TotalNumRows:   output.TotalNumRows,
+		ResultMetadata: output.ResultMetadata,
+	}, nil
+}
+
+func csvField(meta types.ColumnMetadata, value string) types.Field {
+	typeName := strings.ToLower(coalesce(meta.TypeName))
+	if value == "" {
+		if meta.Nullable == columnNoNulls && isStringType(typeName) {
+			return &types.FieldMemberStringValue{}
+		}
+		return &types.FieldMemberIsNull{Value: true}
+	}
+	switch typeName {
+	case "int2", "int4", "int8", "smallint", "integer", "bigint":
+		if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return &types.FieldMemberLongValue{Value: v}
+		}
+	case "float4", "float8", "real", "double precision":
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			return &types.FieldMemberDoubleValue{Value: v}
+		}
+	case "bool", "boolean":
+		if v, err := strconv.ParseBool(value); err == nil {
+			return &types.FieldMemberBooleanValue{Value: v}
+		}
+	}
+	return &types.FieldMemberStringValue{Value: value}
+}
+
+// columnNoNulls is the Nullable of ColumnMetadata for a column that can not be NULL, as in JDBC.
+const columnNoNulls = 0
+
+func isStringType(typeName string) bool {
+	switch typeName {
+	case "varchar", "bpchar", "char", "character", "character varying", "nvarchar", "nchar", "text", "name":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package redshiftdatasqldriver
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
+)
+
+// parsedQuery is a query with its placeholders resolved to Data API parameter names.
+// ? is numbered in order, $n and :n refer to the nth positional argument,
+// and :name and @name refer to the sql.Named argument called name.
+type parsedQuery struct {
+	query        string
+	rewritten    string
+	placeholders []placeholder
+	positional   int
+	named        bool
+}
+
+type placeholder struct {
+	text string
+	name string
+}
+
+func parseQuery(query string) *parsedQuery {
+	q := &parsedQuery{query: query}
+	var b strings.Builder
+	b.Grow(len(query))
+	var questions int
+	for _, tok := range lexSQL(query) {
+		if tok.kind != tokenPlaceholder {
+			b.WriteString(tok.text)
+			continue
+		}
+		var name string
+		if tok.text == "?" {
+			questions++
+			name = strconv.Itoa(questions)
+			q.positional = max(q.positional, questions)
+		} else if n, err := strconv.Atoi(tok.text[1:]); err == nil {
+			name = strconv.Itoa(n)
+			q.positional = max(q.positional, n)
+		} else {
+			name = tok.text[1:]
+			q.named = true
+		}
+		q.placeholders = append(q.placeholders, placeholder{text: tok.text, name: name})
+		b.WriteByte(':')
+		b.WriteString(name)
+	}
+	q.rewritten = b.String()
+	return q
+}
+
+// numInput returns the number of positional arguments, or -1 if the query has named placeholders.
+func (q *parsedQuery) numInput() int {
+	if q.named {
+		return -1
+	}
+	return q.positional
+}
+
+// bind checks that every placeholder has an argument and every argument is used,
+// and returns the rewritten query with its parameters.
+// A query without arguments is returned as is.
+func (q *parsedQuery) bind(args []driver.NamedValue) (string, []types.SqlParameter, error) {
+	if len(args) == 0 {
+		return q.query, nil, nil
+	}
+	names := make([]string, len(args))
+	index := make(map[string]int, len(args))
+	var positional int
+	for i, arg := range args {
+		name := arg.Name
+		if name == "" {
+			positional++
+			name = strconv.Itoa(positional)
+		} else if _, ok := index[name]; ok {
+			return "", nil, fmt.Errorf("argument %q: %w", name, ErrDuplicateArgument)
+		}
+		names[i] = name
+		index[name] = i
+	}
+	used := make([]bool, len(args))
+	for _, p := range q.placeholders {
+		i, ok := index[p.name]
+		if !ok {
+			return "", nil, fmt.Errorf("placeholder %s: %w", p.text, ErrMissingArgument)
+		}
+		used[i] = true
+	}
+	params := make([]types.SqlParameter, 0, len(args))
+	for i, arg := range args {
+		if !used[i] {
+			if arg.Name == "" {
+				return "", nil, fmt.Errorf("argument %s: %w", names[i], ErrUnusedArgument)
+			}
+			return "", nil, fmt.Errorf("argument %q: %w", names[i], ErrUnusedArgument)
+		}
+		params = append(params, types.SqlParameter{
+			Name:  aws.String(names[i]),
+			Value: aws.String(fmt.Sprintf("%v", arg.Value)),
+		})
+	}
+	return q.rewritten, params, nil
+}
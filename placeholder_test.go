@@ -0,0 +1,123 @@
+package redshiftdatasqldriver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
+	"github.com/mashiike/redshift-data-sql-driver/redshiftdatamock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsedQueryBind(t *testing.T) {
+	cases := []struct {
+		casename       string
+		query          string
+		args           []driver.NamedValue
+		expectedSQL    string
+		expectedParams []types.SqlParameter
+		expectedErr    error
+	}{
+		{
+			casename:    "no args",
+			query:       `SELECT @a, ?`,
+			expectedSQL: `SELECT @a, ?`,
+		},
+		{
+			casename: "named reuse",
+			query:    `SELECT * FROM t WHERE a = :id OR b = @id`,
+			args: []driver.NamedValue{
+				{Name: "id", Ordinal: 1, Value: 10},
+			},
+			expectedSQL: `SELECT * FROM t WHERE a = :id OR b = :id`,
+			expectedParams: []types.SqlParameter{
+				{Name: aws.String("id"), Value: aws.String("10")},
+			},
+		},
+		{
+			casename: "mixed",
+			query:    `SELECT * FROM t WHERE a = @name AND b = ? AND c = $2 AND d = ?`,
+			args: []driver.NamedValue{
+				{Name: "name", Ordinal: 1, Value: "hoge"},
+				{Ordinal: 2, Value: 1},
+				{Ordinal: 3, Value: 2},
+			},
+			expectedSQL: `SELECT * FROM t WHERE a = :name AND b = :1 AND c = :2 AND d = :2`,
+			expectedParams: []types.SqlParameter{
+				{Name: aws.String("name"), Value: aws.String("hoge")},
+				{Name: aws.String("1"), Value: aws.String("1")},
+				{Name: aws.String("2"), Value: aws.String("2")},
+			},
+		},
+		{
+			casename: "missing named",
+			query:    `SELECT * FROM t WHERE a = :name`,
+			args: []driver.NamedValue{
+				{Name: "other", Ordinal: 1, Value: 1},
+			},
+			expectedErr: ErrMissingArgument,
+		},
+		{
+			casename: "missing positional",
+			query:    `SELECT * FROM t WHERE a = $2`,
+			args: []driver.NamedValue{
+				{Ordinal: 1, Value: 1},
+			},
+			expectedErr: ErrMissingArgument,
+		},
+		{
+			casename: "unused",
+			query:    `SELECT * FROM t WHERE a = ?`,
+			args: []driver.NamedValue{
+				{Ordinal: 1, Value: 1},
+				{Name: "extra", Ordinal: 2, Value: 2},
+			},
+			expectedErr: ErrUnusedArgument,
+		},
+		{
+			casename: "duplicate",
+			query:    `SELECT * FROM t WHERE a = :a`,
+			args: []driver.NamedValue{
+				{Name: "a", Ordinal: 1, Value: 1},
+				{Name: "a", Ordinal: 2, Value: 2},
+			},
+			expectedErr: ErrDuplicateArgument,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.casename, func(t *testing.T) {
+			sql, params, err := parseQuery(c.query).bind(c.args)
+			if c.expectedErr != nil {
+				require.ErrorIs(t, err, c.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expectedSQL, sql)
+			require.Equal(t, c.expectedParams, params)
+		})
+	}
+}
+
+func TestArgumentMismatchBeforeExecute(t *testing.T) {
+	client := redshiftdatamock.New()
+	client.ExpectExecute(`SELECT name FROM users WHERE id = :id AND status = :1`).
+		WithArgs(sql.Named("id", 1), "active").
+		WillReturnRows(redshiftdatamock.NewRows("name").AddRow("hoge"))
+	db := openTestDB(t, client)
+	restore := requireNoErrorLog(t)
+	defer restore()
+
+	_, err := db.ExecContext(context.Background(), `DELETE FROM users WHERE id = @id`, 1)
+	require.EqualError(t, err, "placeholder @id: missing argument")
+	_, err = db.ExecContext(context.Background(), `DELETE FROM users WHERE id = ?`, 1, 2)
+	require.EqualError(t, err, "argument 2: unused argument")
+
+	var name string
+	err = db.QueryRowContext(context.Background(), `SELECT name FROM users WHERE id = @id AND status = ?`, sql.Named("id", 1), "active").Scan(&name)
+	require.NoError(t, err)
+	require.Equal(t, "hoge", name)
+	require.NoError(t, client.ExpectationsWereMet())
+}
@@ -0,0 +1,68 @@
+package redshiftdatasqldriver
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
+)
+
+// Progress is a snapshot of a running statement, taken on each DescribeStatement poll.
+type Progress struct {
+	StatementID string
+	QueryID     int64
+	Status      types.StatusString
+	// Elapsed is the time since the statement was submitted.
+	Elapsed  time.Duration
+	Describe *redshiftdata.DescribeStatementOutput
+	// Cancel stops waiting for the statement and cancels it on Redshift.
+	// The driver call then returns context.Canceled.
+	Cancel func()
+}
+
+// ProgressFunc receives the progress of the statements run with a context from WithProgress.
+// It is called synchronously from the polling loop and should return quickly.
+type ProgressFunc func(Progress)
+
+type progressKey struct{}
+
+type progressReporter struct {
+	fn     ProgressFunc
+	cancel context.CancelFunc
+}
+
+// WithProgress registers fn to receive the progress of the statements run with ctx.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, &progressReporter{fn: fn})
+}
+
+// withProgressCancel makes the progress Cancel of the statement about to be submitted
+// cancel the returned context.
+func withProgressCancel(ctx context.Context) (context.Context, context.CancelFunc) {
+	r, ok := ctx.Value(progressKey{}).(*progressReporter)
+	if !ok || r.fn == nil {
+		return ctx, func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	return context.WithValue(ctx, progressKey{}, &progressReporter{fn: r.fn, cancel: cancel}), cancel
+}
+
+func reportProgress(ctx context.Context, id *string, desc *redshiftdata.DescribeStatementOutput, queryStart time.Time) {
+	r, ok := ctx.Value(progressKey{}).(*progressReporter)
+	if !ok || r.fn == nil {
+		return
+	}
+	cancel := r.cancel
+	if cancel == nil {
+		cancel = func() {}
+	}
+	r.fn(Progress{
+		StatementID: coalesce(id),
+		QueryID:     desc.RedshiftQueryId,
+		Status:      desc.Status,
+		Elapsed:     time.Since(queryStart),
+		Describe:    desc,
+		Cancel:      cancel,
+	})
+}
@@ -0,0 +1,66 @@
+package redshiftdatasqldriver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgress(t *testing.T) {
+	polls := map[string]int{}
+	var cancelled []string
+	client := &mockRedshiftDataClient{
+		ExecuteStatementFunc: func(ctx context.Context, params *redshiftdata.ExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ExecuteStatementOutput, error) {
+			return &redshiftdata.ExecuteStatementOutput{Id: params.Sql}, nil
+		},
+		DescribeStatementFunc: func(ctx context.Context, params *redshiftdata.DescribeStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.DescribeStatementOutput, error) {
+			polls[*params.Id]++
+			status := types.StatusStringStarted
+			switch {
+			case polls[*params.Id] == 1:
+				status = types.StatusStringSubmitted
+			case *params.Id == "DELETE FROM a" && polls[*params.Id] >= 3:
+				status = types.StatusStringFinished
+			}
+			return &redshiftdata.DescribeStatementOutput{
+				Id:              params.Id,
+				Status:          status,
+				RedshiftQueryId: 42,
+				HasResultSet:    aws.Bool(false),
+			}, nil
+		},
+		CancelStatementFunc: func(ctx context.Context, params *redshiftdata.CancelStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.CancelStatementOutput, error) {
+			cancelled = append(cancelled, *params.Id)
+			return &redshiftdata.CancelStatementOutput{Status: aws.Bool(true)}, nil
+		},
+	}
+	db := openTestDB(t, client, withFastPolling)
+	restore := requireNoErrorLog(t)
+	defer restore()
+
+	var statuses []types.StatusString
+	ctx := WithProgress(context.Background(), func(p Progress) {
+		require.Equal(t, "DELETE FROM a", p.StatementID)
+		require.EqualValues(t, 42, p.QueryID)
+		require.GreaterOrEqual(t, p.Elapsed, time.Duration(0))
+		statuses = append(statuses, p.Status)
+	})
+	_, err := db.ExecContext(ctx, `DELETE FROM a`)
+	require.NoError(t, err)
+	require.Equal(t, []types.StatusString{types.StatusStringSubmitted, types.StatusStringStarted, types.StatusStringFinished}, statuses)
+
+	ctx = WithProgress(context.Background(), func(p Progress) {
+		if p.Status == types.StatusStringStarted {
+			p.Cancel()
+		}
+	})
+	_, err = db.ExecContext(ctx, `SELECT slow`)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 3, polls["SELECT slow"], "two polls and the describe before cancel")
+	require.Equal(t, []string{"SELECT slow"}, cancelled)
+}
@@ -0,0 +1,193 @@
+package redshiftdatasqldriver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
+	"github.com/mashiike/redshift-data-sql-driver/redshiftdatamock"
+	"github.com/stretchr/testify/require"
+)
+
+// These tests are meant to be run with -race.
+
+func TestRaceConcurrentStatements(t *testing.T) {
+	client := redshiftdatamock.New()
+	client.ExpectExecute(`SELECT 1`).
+		WillReturnRows(redshiftdatamock.NewRows("?column?").AddRow(1)).
+		AnyTimes()
+	client.ExpectExecute(`DELETE FROM sessions`).WillReturnResult(1).AnyTimes()
+	db := openTestDB(t, client, withFastPolling)
+	db.SetMaxOpenConns(4)
+	restore := requireNoErrorLog(t)
+	defer restore()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			var n int64
+			require.NoError(t, db.QueryRowContext(context.Background(), `SELECT 1`).Scan(&n))
+			require.EqualValues(t, 1, n)
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := db.ExecContext(context.Background(), `DELETE FROM sessions`)
+			require.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestRaceConcurrentExecInTx(t *testing.T) {
+	const n = 20
+	sqls := make([]string, n)
+	for i := range sqls {
+		sqls[i] = `INSERT INTO foo VALUES (1)`
+	}
+	client := redshiftdatamock.New()
+	client.ExpectBatchExecute(sqls...)
+	restore := requireNoErrorLog(t)
+	defer restore()
+
+	conn := newConn(client, &RedshiftDataConfig{Polling: time.Millisecond})
+	defer conn.Close()
+	tx, err := conn.BeginTx(context.Background(), driver.TxOptions{})
+	require.NoError(t, err)
+	results := make([]driver.Result, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var err error
+			results[i], err = conn.ExecContext(context.Background(), sqls[i], nil)
+			require.NoError(t, err)
+			_, err = conn.QueryContext(context.Background(), `SELECT 1`, nil)
+			require.ErrorIs(t, err, ErrNotSupported)
+			require.True(t, conn.IsValid())
+		}(i)
+	}
+	wg.Wait()
+	require.NoError(t, tx.Commit())
+	require.ErrorIs(t, tx.Rollback(), ErrNotInTx)
+	for _, result := range results {
+		_, err := result.RowsAffected()
+		require.NoError(t, err)
+	}
+	require.NoError(t, client.ExpectationsWereMet())
+}
+
+func TestRaceCommitWithCancelledContext(t *testing.T) {
+	client := redshiftdatamock.New()
+	client.ExpectExecute(`INSERT INTO foo VALUES (1)`).WillReturnResult(1).AnyTimes()
+	db := openTestDB(t, client, withFastPolling)
+	db.SetMaxOpenConns(1)
+
+	for i := 0; i < 50; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		tx, err := db.BeginTx(ctx, nil)
+		require.NoError(t, err)
+		_, err = tx.ExecContext(ctx, `INSERT INTO foo VALUES (1)`)
+		require.NoError(t, err)
+		// database/sql rolls back from its own goroutine when ctx is cancelled,
+		// racing with Commit.
+		go cancel()
+		err = tx.Commit()
+		if err != nil {
+			require.True(t,
+				strings.Contains(err.Error(), "context canceled") || err == sql.ErrTxDone,
+				"unexpected error: %v", err,
+			)
+		}
+		cancel()
+	}
+	tx, err := db.BeginTx(context.Background(), nil)
+	require.NoError(t, err, "connection is usable after the races")
+	require.NoError(t, tx.Rollback())
+}
+
+func TestRaceCloseWhileWaiting(t *testing.T) {
+	client := redshiftdatamock.New()
+	client.ExpectExecute(`VACUUM`).WithLatency(time.Hour).AnyTimes()
+	restore := requireNoErrorLog(t)
+	defer restore()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		conn := newConn(client, &RedshiftDataConfig{Polling: time.Millisecond})
+		submitted := make(chan struct{})
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			ctx := WithProgress(context.Background(), func(p Progress) {
+				select {
+				case <-submitted:
+				default:
+					close(submitted)
+				}
+			})
+			_, err := conn.ExecContext(ctx, `VACUUM`, nil)
+			require.ErrorIs(t, err, ErrConnClosed)
+		}()
+		go func() {
+			defer wg.Done()
+			<-submitted
+			require.NoError(t, conn.Close())
+		}()
+		go func() {
+			defer wg.Done()
+			<-submitted
+			require.NoError(t, conn.Close())
+			require.False(t, conn.IsValid())
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, 10, client.CallCount(redshiftdatamock.OperationCancelStatement))
+}
+
+// blockingSubmitClient holds ExecuteStatement until release is closed.
+type blockingSubmitClient struct {
+	*redshiftdatamock.Client
+	submitting chan struct{}
+	release    chan struct{}
+}
+
+func (c *blockingSubmitClient) ExecuteStatement(ctx context.Context, params *redshiftdata.ExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ExecuteStatementOutput, error) {
+	close(c.submitting)
+	<-c.release
+	return c.Client.ExecuteStatement(ctx, params, optFns...)
+}
+
+func TestRaceCloseWhileSubmitting(t *testing.T) {
+	mock := redshiftdatamock.New()
+	mock.ExpectExecute(`VACUUM`).WithLatency(time.Hour).AnyTimes()
+	restore := requireNoErrorLog(t)
+	defer restore()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		client := &blockingSubmitClient{Client: mock, submitting: make(chan struct{}), release: make(chan struct{})}
+		conn := newConn(client, &RedshiftDataConfig{Polling: time.Millisecond})
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := conn.ExecContext(context.Background(), `VACUUM`, nil)
+			require.ErrorIs(t, err, ErrConnClosed)
+		}()
+		go func() {
+			defer wg.Done()
+			<-client.submitting
+			require.NoError(t, conn.Close())
+			close(client.release)
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, 10, mock.CallCount(redshiftdatamock.OperationCancelStatement))
+}
@@ -0,0 +1,110 @@
+package redshiftdatasqldriver
+
+import (
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
+)
+
+// SQLLogMode controls how SQL text appears in log events.
+type SQLLogMode int
+
+const (
+	// SQLLogModeRaw logs the SQL text as is.
+	SQLLogModeRaw SQLLogMode = iota
+	// SQLLogModeRedact replaces string, dollar-quoted and numeric literals with '?'.
+	SQLLogModeRedact
+	// SQLLogModeHash logs only the sql_hash attribute.
+	SQLLogModeHash
+)
+
+func (m SQLLogMode) String() string {
+	switch m {
+	case SQLLogModeRaw:
+		return "raw"
+	case SQLLogModeRedact:
+		return "redact"
+	case SQLLogModeHash:
+		return "hash"
+	default:
+		return "unknown"
+	}
+}
+
+func (cfg *RedshiftDataConfig) sqlLogAttrs(sql string) []slog.Attr {
+	attrs := []slog.Attr{sqlHashAttr(sql)}
+	if s, ok := cfg.loggableSQL(sql); ok {
+		attrs = append(attrs, slog.String("sql", s))
+	}
+	return attrs
+}
+
+// loggableSQL returns sql as it may appear in logs and traces under the configured SQLLogMode.
+func (cfg *RedshiftDataConfig) loggableSQL(sql string) (string, bool) {
+	var mode SQLLogMode
+	var maxLength int
+	if cfg != nil {
+		mode = cfg.SQLLogMode
+		maxLength = cfg.SQLLogMaxLength
+	}
+	switch mode {
+	case SQLLogModeHash:
+		return "", false
+	case SQLLogModeRedact:
+		sql = redactSQL(sql)
+	}
+	return truncateSQL(sql, maxLength), true
+}
+
+func (cfg *RedshiftDataConfig) parameterLogAttrs(params []types.SqlParameter) []slog.Attr {
+	if len(params) == 0 {
+		return nil
+	}
+	if cfg == nil || !cfg.LogParameterValues {
+		names := make([]string, 0, len(params))
+		for _, p := range params {
+			names = append(names, coalesce(p.Name))
+		}
+		return []slog.Attr{slog.Any("parameters", names)}
+	}
+	values := make([]slog.Attr, 0, len(params))
+	for _, p := range params {
+		values = append(values, slog.String(coalesce(p.Name), coalesce(p.Value)))
+	}
+	return []slog.Attr{{Key: "parameters", Value: slog.GroupValue(values...)}}
+}
+
+func truncateSQL(sql string, maxLength int) string {
+	if maxLength <= 0 || len(sql) <= maxLength {
+		return sql
+	}
+	cut := maxLength
+	for cut > 0 && !utf8.RuneStart(sql[cut]) {
+		cut--
+	}
+	return sql[:cut] + "..."
+}
+
+// redactSQL replaces literals in sql with '?', leaving keywords, identifiers,
+// placeholders and comments untouched. It fails closed: everything from an unterminated
+// string, identifier or comment on is replaced by a single '?', since where the literals
+// end in it can not be told.
+func redactSQL(sql string) string {
+	var b strings.Builder
+	b.Grow(len(sql))
+	for _, tok := range lexSQL(sql) {
+		if tok.unterminated {
+			b.WriteByte('?')
+			break
+		}
+		switch tok.kind {
+		case tokenString, tokenDollarString, tokenNumber:
+			b.WriteByte('?')
+		default:
+			b.WriteString(tok.text)
+		}
+	}
+	return b.String()
+}
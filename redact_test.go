@@ -0,0 +1,159 @@
+package redshiftdatasqldriver
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactSQL(t *testing.T) {
+	cases := []struct {
+		casename string
+		sql      string
+		expected string
+	}{
+		{
+			casename: "string literal",
+			sql:      `SELECT * FROM users WHERE email = 'hoge@example.com' AND name = 'O''Reilly'`,
+			expected: `SELECT * FROM users WHERE email = ? AND name = ?`,
+		},
+		{
+			casename: "escape string",
+			sql:      `SELECT E'it\'s secret', e'x'`,
+			expected: `SELECT ?, ?`,
+		},
+		{
+			casename: "numbers",
+			sql:      `SELECT col1, 42, 3.14, 1e-5 FROM t2 LIMIT 10`,
+			expected: `SELECT col1, ?, ?, ? FROM t2 LIMIT ?`,
+		},
+		{
+			casename: "placeholders and identifiers",
+			sql:      `SELECT "user 123" FROM t WHERE id = :id AND age > $1 AND x = :2 AND y = ? AND z::int = 7`,
+			expected: `SELECT "user 123" FROM t WHERE id = :id AND age > $1 AND x = :2 AND y = ? AND z::int = ?`,
+		},
+		{
+			casename: "dollar quoted",
+			sql:      `CREATE PROCEDURE p() AS $body$ BEGIN RAISE INFO 'secret'; END; $body$ LANGUAGE plpgsql`,
+			expected: `CREATE PROCEDURE p() AS ? LANGUAGE plpgsql`,
+		},
+		{
+			casename: "comments",
+			sql:      "SELECT 1 -- it's 2\n/* don't */ FROM t",
+			expected: "SELECT ? -- it's 2\n/* don't */ FROM t",
+		},
+		{
+			casename: "backslash escaped quote",
+			sql:      `SELECT * FROM u WHERE note = 'it\'s my ssn 123-45-6789'`,
+			expected: `SELECT * FROM u WHERE note = ?`,
+		},
+		{
+			casename: "unterminated string",
+			sql:      `SELECT * FROM u WHERE note = 'it''s my ssn 123-45-6789`,
+			expected: `SELECT * FROM u WHERE note = ?`,
+		},
+		{
+			casename: "unterminated identifier",
+			sql:      `SELECT "note FROM u WHERE ssn = '123-45-6789'`,
+			expected: `SELECT ?`,
+		},
+		{
+			casename: "unterminated comment",
+			sql:      `SELECT 1 /* ssn = '123-45-6789'`,
+			expected: `SELECT ? ?`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.casename, func(t *testing.T) {
+			require.Equal(t, c.expected, redactSQL(c.sql))
+		})
+	}
+}
+
+func TestTruncateSQL(t *testing.T) {
+	require.Equal(t, "SELECT 1", truncateSQL("SELECT 1", 0))
+	require.Equal(t, "SELECT 1", truncateSQL("SELECT 1", 8))
+	require.Equal(t, "SELECT...", truncateSQL("SELECT 1", 6))
+	require.Equal(t, "SELECT '...", truncateSQL("SELECT 'あ'", 9))
+}
+
+func TestSQLLogMode(t *testing.T) {
+	client := &mockRedshiftDataClient{
+		ExecuteStatementFunc: func(ctx context.Context, params *redshiftdata.ExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ExecuteStatementOutput, error) {
+			return &redshiftdata.ExecuteStatementOutput{
+				Id: aws.String("dummy"),
+			}, nil
+		},
+		DescribeStatementFunc: func(ctx context.Context, params *redshiftdata.DescribeStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.DescribeStatementOutput, error) {
+			return &redshiftdata.DescribeStatementOutput{
+				Id:           params.Id,
+				Status:       types.StatusStringFinished,
+				HasResultSet: aws.Bool(false),
+			}, nil
+		},
+	}
+	query := `UPDATE users SET email = 'hoge@example.com' WHERE id = :id`
+	cases := []struct {
+		casename    string
+		mode        SQLLogMode
+		allowValues bool
+		contains    []string
+		notContains []string
+	}{
+		{
+			casename:    "raw",
+			mode:        SQLLogModeRaw,
+			contains:    []string{"hoge@example.com", "parameters=[id]"},
+			notContains: []string{"secret-id"},
+		},
+		{
+			casename:    "redact",
+			mode:        SQLLogModeRedact,
+			contains:    []string{`"UPDATE users SET email = ? WHERE id = :id"`, "sql_hash="},
+			notContains: []string{"hoge@example.com", "secret-id"},
+		},
+		{
+			casename:    "hash",
+			mode:        SQLLogModeHash,
+			contains:    []string{"sql_hash="},
+			notContains: []string{"UPDATE users", "secret-id"},
+		},
+		{
+			casename:    "redact with parameter values",
+			mode:        SQLLogModeRedact,
+			allowValues: true,
+			contains:    []string{`parameters="[id=secret-id]"`},
+			notContains: []string{"hoge@example.com"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.casename, func(t *testing.T) {
+			var debug strings.Builder
+			cfg := (&RedshiftDataConfig{
+				WorkgroupName: aws.String("default"),
+				Database:      aws.String("dev"),
+			}).WithRedshiftDataClientConstructor(func(ctx context.Context, cfg *RedshiftDataConfig) (RedshiftDataClient, error) {
+				return client, nil
+			}).WithDebugLogger(log.New(&debug, "", 0)).
+				WithSQLLogMode(c.mode).
+				WithLogParameterValues(c.allowValues)
+			db := sql.OpenDB(NewConnector(cfg))
+			defer db.Close()
+			_, err := db.ExecContext(context.Background(), query, sql.Named("id", "secret-id"))
+			require.NoError(t, err)
+			for _, s := range c.contains {
+				require.Contains(t, debug.String(), s)
+			}
+			for _, s := range c.notContains {
+				require.NotContains(t, debug.String(), s)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+// Package redshiftdatacopy builds COPY and UNLOAD statements with quoted identifiers and literals,
+// and runs them through database/sql.
+package redshiftdatacopy
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mashiike/redshift-data-sql-driver/internal/sqlquote"
+)
+
+// Format is the file format of COPY and UNLOAD. The zero value is Redshift's pipe-delimited text.
+type Format string
+
+const (
+	FormatText    Format = ""
+	FormatCSV     Format = "CSV"
+	FormatJSON    Format = "JSON"
+	FormatParquet Format = "PARQUET"
+)
+
+// Compression is the compression of the files read by COPY or written by UNLOAD.
+type Compression string
+
+const (
+	CompressionNone  Compression = ""
+	CompressionGzip  Compression = "GZIP"
+	CompressionBzip2 Compression = "BZIP2"
+	CompressionZstd  Compression = "ZSTD"
+)
+
+// Execer runs a statement; *sql.DB and *sql.Conn satisfy it.
+//
+// A *sql.Tx satisfies it too, but Exec rejects one with ErrInTransaction: the driver only queues
+// the statements of a transaction until Commit, so there is no row count to return yet.
+// Queue the statement made by SQL with ExecContext of the transaction instead.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+var (
+	ErrInvalidCommand = errors.New("invalid command")
+	ErrInTransaction  = errors.New("exec in a transaction")
+)
+
+// exec runs query and returns the rows it affected, which the driver takes from ResultRows of DescribeStatement.
+func exec(ctx context.Context, db Execer, query string) (int64, error) {
+	if _, ok := db.(*sql.Tx); ok {
+		return 0, fmt.Errorf("%w: the row count is not known before commit, queue SQL() with ExecContext of the transaction", ErrInTransaction)
+	}
+	result, err := db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func invalid(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidCommand, fmt.Sprintf(format, args...))
+}
+
+func writeCommon(b *strings.Builder, iamRole string, compression Compression, manifest bool) {
+	if iamRole == "" {
+		b.WriteString(" IAM_ROLE default")
+	} else {
+		b.WriteString(" IAM_ROLE ")
+		b.WriteString(sqlquote.Literal(iamRole))
+	}
+	if manifest {
+		b.WriteString(" MANIFEST")
+	}
+	if compression != CompressionNone {
+		b.WriteString(" ")
+		b.WriteString(string(compression))
+	}
+}
+
+func validateCompression(compression Compression) error {
+	switch compression {
+	case CompressionNone, CompressionGzip, CompressionBzip2, CompressionZstd:
+		return nil
+	}
+	return invalid("unknown compression %q", compression)
+}
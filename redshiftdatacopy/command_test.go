@@ -0,0 +1,177 @@
+package redshiftdatacopy_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	redshiftdatasqldriver "github.com/mashiike/redshift-data-sql-driver"
+	"github.com/mashiike/redshift-data-sql-driver/redshiftdatacopy"
+	"github.com/mashiike/redshift-data-sql-driver/redshiftdatamock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopySQL(t *testing.T) {
+	cases := []struct {
+		casename string
+		command  *redshiftdatacopy.CopyCommand
+		expected string
+	}{
+		{
+			casename: "default",
+			command:  redshiftdatacopy.Copy("users", "s3://bucket/users/"),
+			expected: `COPY "users" FROM 's3://bucket/users/' IAM_ROLE default`,
+		},
+		{
+			casename: "csv",
+			command: redshiftdatacopy.Copy("public.users", "s3://bucket/it's/manifest").
+				WithColumns("id", `na"me`).
+				WithIAMRole("arn:aws:iam::123456789012:role/copy").
+				WithFormat(redshiftdatacopy.FormatCSV).
+				WithManifest().
+				WithCompression(redshiftdatacopy.CompressionGzip).
+				WithIgnoreHeader(1).
+				WithRegion("us-west-2"),
+			expected: `COPY "public"."users" ("id", "na""me") FROM 's3://bucket/it''s/manifest' FORMAT AS CSV IAM_ROLE 'arn:aws:iam::123456789012:role/copy' MANIFEST GZIP IGNOREHEADER 1 REGION 'us-west-2'`,
+		},
+		{
+			casename: "json",
+			command:  redshiftdatacopy.Copy("events", "s3://bucket/events/").WithFormat(redshiftdatacopy.FormatJSON),
+			expected: `COPY "events" FROM 's3://bucket/events/' FORMAT AS JSON 'auto' IAM_ROLE default`,
+		},
+		{
+			casename: "parquet",
+			command: redshiftdatacopy.Copy("events", "s3://bucket/events/").
+				WithFormat(redshiftdatacopy.FormatParquet),
+			expected: `COPY "events" FROM 's3://bucket/events/' FORMAT AS PARQUET IAM_ROLE default`,
+		},
+		{
+			casename: "null as and time format",
+			command: redshiftdatacopy.Copy("events", "s3://bucket/events.csv").
+				WithFormat(redshiftdatacopy.FormatCSV).
+				WithNullAs(`\N`).
+				WithTimeFormat("auto"),
+			expected: `COPY "events" FROM 's3://bucket/events.csv' FORMAT AS CSV IAM_ROLE default NULL AS '\\N' TIMEFORMAT 'auto'`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.casename, func(t *testing.T) {
+			actual, err := c.command.SQL()
+			require.NoError(t, err)
+			require.Equal(t, c.expected, actual)
+		})
+	}
+}
+
+func TestUnloadSQL(t *testing.T) {
+	cases := []struct {
+		casename string
+		command  *redshiftdatacopy.UnloadCommand
+		expected string
+	}{
+		{
+			casename: "default",
+			command:  redshiftdatacopy.Unload(`SELECT * FROM users`, "s3://bucket/users_"),
+			expected: `UNLOAD ('SELECT * FROM users') TO 's3://bucket/users_' IAM_ROLE default`,
+		},
+		{
+			casename: "parquet",
+			command: redshiftdatacopy.Unload(`SELECT * FROM events WHERE kind = 'click' AND path LIKE '%\_%'`, "s3://bucket/events/").
+				WithIAMRole("arn:aws:iam::123456789012:role/unload").
+				WithFormat(redshiftdatacopy.FormatParquet).
+				WithPartitionBy("year", "month").
+				WithManifest().
+				WithMaxFileSizeMB(256).
+				WithAllowOverwrite().
+				WithRegion("us-west-2"),
+			expected: `UNLOAD ('SELECT * FROM events WHERE kind = ''click'' AND path LIKE ''%\\_%''') TO 's3://bucket/events/' FORMAT AS PARQUET PARTITION BY ("year", "month") IAM_ROLE 'arn:aws:iam::123456789012:role/unload' MANIFEST MAXFILESIZE 256 MB ALLOWOVERWRITE REGION 'us-west-2'`,
+		},
+		{
+			casename: "csv",
+			command: redshiftdatacopy.Unload(`SELECT id FROM users ORDER BY id`, "s3://bucket/users_").
+				WithFormat(redshiftdatacopy.FormatCSV).
+				WithCompression(redshiftdatacopy.CompressionZstd).
+				WithHeader().
+				WithParallelOff(),
+			expected: `UNLOAD ('SELECT id FROM users ORDER BY id') TO 's3://bucket/users_' FORMAT AS CSV IAM_ROLE default ZSTD HEADER PARALLEL OFF`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.casename, func(t *testing.T) {
+			actual, err := c.command.SQL()
+			require.NoError(t, err)
+			require.Equal(t, c.expected, actual)
+		})
+	}
+}
+
+func TestInvalidCommand(t *testing.T) {
+	for _, build := range []func() (string, error){
+		redshiftdatacopy.Copy("", "s3://bucket/").SQL,
+		redshiftdatacopy.Copy("users", "s3://bucket/").WithFormat("XML").SQL,
+		redshiftdatacopy.Copy("users", "s3://bucket/").WithFormat(redshiftdatacopy.FormatParquet).WithIgnoreHeader(1).SQL,
+		redshiftdatacopy.Copy("users", "s3://bucket/").WithFormat(redshiftdatacopy.FormatParquet).WithCompression(redshiftdatacopy.CompressionGzip).SQL,
+		redshiftdatacopy.Copy("users", "s3://bucket/").WithFormat(redshiftdatacopy.FormatParquet).WithTimeFormat("auto").SQL,
+		redshiftdatacopy.Copy("users", "s3://bucket/").WithFormat(redshiftdatacopy.FormatJSON).WithNullAs("").SQL,
+		redshiftdatacopy.Unload("SELECT 1", "").SQL,
+		redshiftdatacopy.Unload("SELECT 1", "s3://bucket/").WithFormat(redshiftdatacopy.FormatParquet).WithCompression(redshiftdatacopy.CompressionGzip).SQL,
+		redshiftdatacopy.Unload("SELECT 1", "s3://bucket/").WithCompression("LZOP").SQL,
+		redshiftdatacopy.Unload("SELECT 1", "s3://bucket/").WithMaxFileSizeMB(1).SQL,
+	} {
+		_, err := build()
+		require.ErrorIs(t, err, redshiftdatacopy.ErrInvalidCommand)
+	}
+}
+
+func TestExec(t *testing.T) {
+	client := redshiftdatamock.New()
+	client.ExpectExecute(`COPY "users" FROM 's3://bucket/users/' FORMAT AS CSV IAM_ROLE default`).WillReturnResult(120)
+	client.ExpectExecute(`UNLOAD ('SELECT * FROM users') TO 's3://bucket/users_' IAM_ROLE default`).WillReturnResult(120)
+	cfg := (&redshiftdatasqldriver.RedshiftDataConfig{
+		WorkgroupName: aws.String("default"),
+		Database:      aws.String("dev"),
+	}).WithRedshiftDataClientConstructor(func(ctx context.Context, cfg *redshiftdatasqldriver.RedshiftDataConfig) (redshiftdatasqldriver.RedshiftDataClient, error) {
+		return client, nil
+	})
+	db := sql.OpenDB(redshiftdatasqldriver.NewConnector(cfg))
+	defer db.Close()
+
+	loaded, err := redshiftdatacopy.Copy("users", "s3://bucket/users/").WithFormat(redshiftdatacopy.FormatCSV).Exec(context.Background(), db)
+	require.NoError(t, err)
+	require.EqualValues(t, 120, loaded)
+	unloaded, err := redshiftdatacopy.Unload(`SELECT * FROM users`, "s3://bucket/users_").Exec(context.Background(), db)
+	require.NoError(t, err)
+	require.EqualValues(t, 120, unloaded)
+	require.NoError(t, client.ExpectationsWereMet())
+}
+
+func TestExecInTransaction(t *testing.T) {
+	client := redshiftdatamock.New()
+	client.ExpectExecute(`COPY "users" FROM 's3://bucket/users/' FORMAT AS CSV IAM_ROLE default`).WillReturnResult(120)
+	cfg := (&redshiftdatasqldriver.RedshiftDataConfig{
+		WorkgroupName: aws.String("default"),
+		Database:      aws.String("dev"),
+	}).WithRedshiftDataClientConstructor(func(ctx context.Context, cfg *redshiftdatasqldriver.RedshiftDataConfig) (redshiftdatasqldriver.RedshiftDataClient, error) {
+		return client, nil
+	})
+	db := sql.OpenDB(redshiftdatasqldriver.NewConnector(cfg))
+	defer db.Close()
+	ctx := context.Background()
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	copyCommand := redshiftdatacopy.Copy("users", "s3://bucket/users/").WithFormat(redshiftdatacopy.FormatCSV)
+	_, err = copyCommand.Exec(ctx, tx)
+	require.ErrorIs(t, err, redshiftdatacopy.ErrInTransaction)
+
+	query, err := copyCommand.SQL()
+	require.NoError(t, err)
+	result, err := tx.ExecContext(ctx, query)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+	loaded, err := result.RowsAffected()
+	require.NoError(t, err)
+	require.EqualValues(t, 120, loaded)
+	require.NoError(t, client.ExpectationsWereMet())
+}
@@ -0,0 +1,170 @@
+package redshiftdatacopy
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/mashiike/redshift-data-sql-driver/internal/sqlquote"
+)
+
+// CopyCommand is a COPY statement loading files from S3 into a table.
+type CopyCommand struct {
+	// Table may be schema qualified as schema.table.
+	Table   string
+	Columns []string
+	// From is the S3 prefix of the files, or the manifest file when Manifest is set.
+	From     string
+	IAMRole  string
+	Format   Format
+	Manifest bool
+	// Compression can't be used with FormatParquet, which is compressed internally.
+	Compression Compression
+	// JSONPaths is the jsonpaths file for FormatJSON. default = 'auto'
+	JSONPaths string
+	// IgnoreHeader skips the first lines of each file.
+	IgnoreHeader int
+	// NullAs is the text loaded as NULL, for text and CSV. default = Redshift's \N
+	NullAs *string
+	// TimeFormat is the format of TIMESTAMP values, such as 'auto'. default = Redshift's YYYY-MM-DD HH:MI:SS
+	TimeFormat string
+	// Region is the region of the bucket if it differs from the cluster's.
+	Region string
+}
+
+// Copy returns a COPY of the files at from into table.
+func Copy(table, from string) *CopyCommand {
+	return &CopyCommand{
+		Table: table,
+		From:  from,
+	}
+}
+
+func (c *CopyCommand) WithColumns(columns ...string) *CopyCommand {
+	c.Columns = append(c.Columns, columns...)
+	return c
+}
+
+func (c *CopyCommand) WithIAMRole(arn string) *CopyCommand {
+	c.IAMRole = arn
+	return c
+}
+
+func (c *CopyCommand) WithFormat(format Format) *CopyCommand {
+	c.Format = format
+	return c
+}
+
+func (c *CopyCommand) WithManifest() *CopyCommand {
+	c.Manifest = true
+	return c
+}
+
+func (c *CopyCommand) WithCompression(compression Compression) *CopyCommand {
+	c.Compression = compression
+	return c
+}
+
+func (c *CopyCommand) WithJSONPaths(path string) *CopyCommand {
+	c.JSONPaths = path
+	return c
+}
+
+func (c *CopyCommand) WithIgnoreHeader(lines int) *CopyCommand {
+	c.IgnoreHeader = lines
+	return c
+}
+
+func (c *CopyCommand) WithNullAs(text string) *CopyCommand {
+	c.NullAs = &text
+	return c
+}
+
+func (c *CopyCommand) WithTimeFormat(format string) *CopyCommand {
+	c.TimeFormat = format
+	return c
+}
+
+func (c *CopyCommand) WithRegion(region string) *CopyCommand {
+	c.Region = region
+	return c
+}
+
+// SQL returns the COPY statement, or an error wrapping ErrInvalidCommand.
+func (c *CopyCommand) SQL() (string, error) {
+	if c.Table == "" {
+		return "", invalid("copy: table is empty")
+	}
+	if c.From == "" {
+		return "", invalid("copy: from is empty")
+	}
+	if err := validateCompression(c.Compression); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	b.WriteString("COPY ")
+	b.WriteString(sqlquote.Table(c.Table))
+	if len(c.Columns) > 0 {
+		b.WriteString(" (")
+		b.WriteString(sqlquote.Idents(c.Columns))
+		b.WriteString(")")
+	}
+	b.WriteString(" FROM ")
+	b.WriteString(sqlquote.Literal(c.From))
+	switch c.Format {
+	case FormatText:
+	case FormatCSV:
+		b.WriteString(" FORMAT AS CSV")
+	case FormatJSON:
+		b.WriteString(" FORMAT AS JSON ")
+		if c.JSONPaths == "" {
+			b.WriteString("'auto'")
+		} else {
+			b.WriteString(sqlquote.Literal(c.JSONPaths))
+		}
+	case FormatParquet:
+		switch {
+		case c.Compression != CompressionNone:
+			return "", invalid("copy: compression with parquet")
+		case c.IgnoreHeader > 0:
+			return "", invalid("copy: ignore header with parquet")
+		case c.NullAs != nil:
+			return "", invalid("copy: null as with parquet")
+		case c.TimeFormat != "":
+			return "", invalid("copy: time format with parquet")
+		}
+		b.WriteString(" FORMAT AS PARQUET")
+	default:
+		return "", invalid("copy: unknown format %q", c.Format)
+	}
+	if c.NullAs != nil && c.Format == FormatJSON {
+		return "", invalid("copy: null as with json")
+	}
+	writeCommon(&b, c.IAMRole, c.Compression, c.Manifest)
+	if c.IgnoreHeader > 0 {
+		b.WriteString(" IGNOREHEADER ")
+		b.WriteString(strconv.Itoa(c.IgnoreHeader))
+	}
+	if c.NullAs != nil {
+		b.WriteString(" NULL AS ")
+		b.WriteString(sqlquote.Literal(*c.NullAs))
+	}
+	if c.TimeFormat != "" {
+		b.WriteString(" TIMEFORMAT ")
+		b.WriteString(sqlquote.Literal(c.TimeFormat))
+	}
+	if c.Region != "" {
+		b.WriteString(" REGION ")
+		b.WriteString(sqlquote.Literal(c.Region))
+	}
+	return b.String(), nil
+}
+
+// Exec runs the COPY and returns the number of rows loaded.
+func (c *CopyCommand) Exec(ctx context.Context, db Execer) (int64, error) {
+	query, err := c.SQL()
+	if err != nil {
+		return 0, err
+	}
+	return exec(ctx, db, query)
+}
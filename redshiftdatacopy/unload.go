@@ -0,0 +1,160 @@
+package redshiftdatacopy
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/mashiike/redshift-data-sql-driver/internal/sqlquote"
+)
+
+// UnloadCommand is an UNLOAD statement writing the result of a query to files in S3.
+type UnloadCommand struct {
+	Query string
+	// To is the S3 prefix of the files.
+	To       string
+	IAMRole  string
+	Format   Format
+	Manifest bool
+	// Compression can't be used with FormatParquet.
+	Compression Compression
+	// Header writes a header line to CSV and text files.
+	Header      bool
+	PartitionBy []string
+	// MaxFileSizeMB bounds the size of each file, from 5 to 6200 MB. default = 6200 MB
+	MaxFileSizeMB  int
+	AllowOverwrite bool
+	// ParallelOff writes the files serially, sorted by the ORDER BY of the query.
+	ParallelOff bool
+	// Region is the region of the bucket if it differs from the cluster's.
+	Region string
+}
+
+// Unload returns an UNLOAD of the result of query to the files at to.
+func Unload(query, to string) *UnloadCommand {
+	return &UnloadCommand{
+		Query: query,
+		To:    to,
+	}
+}
+
+func (u *UnloadCommand) WithIAMRole(arn string) *UnloadCommand {
+	u.IAMRole = arn
+	return u
+}
+
+func (u *UnloadCommand) WithFormat(format Format) *UnloadCommand {
+	u.Format = format
+	return u
+}
+
+func (u *UnloadCommand) WithManifest() *UnloadCommand {
+	u.Manifest = true
+	return u
+}
+
+func (u *UnloadCommand) WithCompression(compression Compression) *UnloadCommand {
+	u.Compression = compression
+	return u
+}
+
+func (u *UnloadCommand) WithHeader() *UnloadCommand {
+	u.Header = true
+	return u
+}
+
+func (u *UnloadCommand) WithPartitionBy(columns ...string) *UnloadCommand {
+	u.PartitionBy = append(u.PartitionBy, columns...)
+	return u
+}
+
+func (u *UnloadCommand) WithMaxFileSizeMB(mb int) *UnloadCommand {
+	u.MaxFileSizeMB = mb
+	return u
+}
+
+func (u *UnloadCommand) WithAllowOverwrite() *UnloadCommand {
+	u.AllowOverwrite = true
+	return u
+}
+
+func (u *UnloadCommand) WithParallelOff() *UnloadCommand {
+	u.ParallelOff = true
+	return u
+}
+
+func (u *UnloadCommand) WithRegion(region string) *UnloadCommand {
+	u.Region = region
+	return u
+}
+
+// SQL returns the UNLOAD statement, or an error wrapping ErrInvalidCommand.
+func (u *UnloadCommand) SQL() (string, error) {
+	if u.Query == "" {
+		return "", invalid("unload: query is empty")
+	}
+	if u.To == "" {
+		return "", invalid("unload: to is empty")
+	}
+	if err := validateCompression(u.Compression); err != nil {
+		return "", err
+	}
+	if u.MaxFileSizeMB != 0 && (u.MaxFileSizeMB < 5 || u.MaxFileSizeMB > 6200) {
+		return "", invalid("unload: max file size %d MB out of 5-6200 MB", u.MaxFileSizeMB)
+	}
+	var b strings.Builder
+	b.WriteString("UNLOAD (")
+	b.WriteString(sqlquote.Literal(u.Query))
+	b.WriteString(") TO ")
+	b.WriteString(sqlquote.Literal(u.To))
+	switch u.Format {
+	case FormatText:
+	case FormatCSV, FormatJSON:
+		b.WriteString(" FORMAT AS ")
+		b.WriteString(string(u.Format))
+	case FormatParquet:
+		if u.Compression != CompressionNone {
+			return "", invalid("unload: compression with parquet")
+		}
+		if u.Header {
+			return "", invalid("unload: header with parquet")
+		}
+		b.WriteString(" FORMAT AS PARQUET")
+	default:
+		return "", invalid("unload: unknown format %q", u.Format)
+	}
+	if len(u.PartitionBy) > 0 {
+		b.WriteString(" PARTITION BY (")
+		b.WriteString(sqlquote.Idents(u.PartitionBy))
+		b.WriteString(")")
+	}
+	writeCommon(&b, u.IAMRole, u.Compression, u.Manifest)
+	if u.Header {
+		b.WriteString(" HEADER")
+	}
+	if u.MaxFileSizeMB != 0 {
+		b.WriteString(" MAXFILESIZE ")
+		b.WriteString(strconv.Itoa(u.MaxFileSizeMB))
+		b.WriteString(" MB")
+	}
+	if u.AllowOverwrite {
+		b.WriteString(" ALLOWOVERWRITE")
+	}
+	if u.ParallelOff {
+		b.WriteString(" PARALLEL OFF")
+	}
+	if u.Region != "" {
+		b.WriteString(" REGION ")
+		b.WriteString(sqlquote.Literal(u.Region))
+	}
+	return b.String(), nil
+}
+
+// Exec runs the UNLOAD and returns the number of rows unloaded.
+func (u *UnloadCommand) Exec(ctx context.Context, db Execer) (int64, error) {
+	query, err := u.SQL()
+	if err != nil {
+		return 0, err
+	}
+	return exec(ctx, db, query)
+}
@@ -0,0 +1,113 @@
+// Package redshiftdatametrics reports the driver's MetricsRecorder measurements as
+// OpenTelemetry metrics.
+package redshiftdatametrics
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
+	redshiftdatasqldriver "github.com/mashiike/redshift-data-sql-driver"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const meterName = "github.com/mashiike/redshift-data-sql-driver"
+
+// Recorder implements redshiftdatasqldriver.MetricsRecorder with OpenTelemetry instruments:
+//
+//   - redshift_data.api.calls: Data API calls by operation and error
+//   - redshift_data.statement.polls: DescribeStatement calls per statement
+//   - redshift_data.statement.queue_time: seconds before the statement was STARTED
+//   - redshift_data.statement.execution_time: seconds from STARTED to the final status
+//   - redshift_data.statement.result_rows: ResultRows per statement
+//   - redshift_data.statement.result_size: ResultSize per statement
+//   - redshift_data.statement.failures: statements that ended FAILED or ABORTED, by status
+type Recorder struct {
+	apiCalls      metric.Int64Counter
+	polls         metric.Int64Histogram
+	queueTime     metric.Float64Histogram
+	executionTime metric.Float64Histogram
+	resultRows    metric.Int64Histogram
+	resultSize    metric.Int64Histogram
+	failures      metric.Int64Counter
+	attrs         []attribute.KeyValue
+}
+
+var _ redshiftdatasqldriver.MetricsRecorder = (*Recorder)(nil)
+
+// NewRecorder creates the instruments on a meter from mp.
+// attrs are added to every measurement, for example to tell databases apart.
+func NewRecorder(mp metric.MeterProvider, attrs ...attribute.KeyValue) (*Recorder, error) {
+	meter := mp.Meter(meterName)
+	r := &Recorder{
+		attrs: attrs,
+	}
+	var err error
+	if r.apiCalls, err = meter.Int64Counter("redshift_data.api.calls",
+		metric.WithDescription("Number of Redshift Data API calls."),
+		metric.WithUnit("{call}"),
+	); err != nil {
+		return nil, err
+	}
+	if r.polls, err = meter.Int64Histogram("redshift_data.statement.polls",
+		metric.WithDescription("Number of DescribeStatement calls per statement."),
+		metric.WithUnit("{call}"),
+	); err != nil {
+		return nil, err
+	}
+	if r.queueTime, err = meter.Float64Histogram("redshift_data.statement.queue_time",
+		metric.WithDescription("Time a statement spent before it was STARTED."),
+		metric.WithUnit("s"),
+	); err != nil {
+		return nil, err
+	}
+	if r.executionTime, err = meter.Float64Histogram("redshift_data.statement.execution_time",
+		metric.WithDescription("Time a statement spent from STARTED until it finished."),
+		metric.WithUnit("s"),
+	); err != nil {
+		return nil, err
+	}
+	if r.resultRows, err = meter.Int64Histogram("redshift_data.statement.result_rows",
+		metric.WithDescription("Number of rows in the statement result."),
+		metric.WithUnit("{row}"),
+	); err != nil {
+		return nil, err
+	}
+	if r.resultSize, err = meter.Int64Histogram("redshift_data.statement.result_size",
+		metric.WithDescription("Size of the statement result."),
+		metric.WithUnit("By"),
+	); err != nil {
+		return nil, err
+	}
+	if r.failures, err = meter.Int64Counter("redshift_data.statement.failures",
+		metric.WithDescription("Number of statements that ended FAILED or ABORTED."),
+		metric.WithUnit("{statement}"),
+	); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Recorder) RecordAPICall(ctx context.Context, operation string, err error) {
+	attrs := append([]attribute.KeyValue{
+		attribute.String("operation", operation),
+		attribute.Bool("error", err != nil),
+	}, r.attrs...)
+	r.apiCalls.Add(ctx, 1, metric.WithAttributes(attrs...))
+}
+
+func (r *Recorder) RecordStatement(ctx context.Context, m redshiftdatasqldriver.StatementMetrics) {
+	attrs := metric.WithAttributes(append([]attribute.KeyValue{
+		attribute.String("status", string(m.Status)),
+	}, r.attrs...)...)
+	r.polls.Record(ctx, int64(m.Polls), attrs)
+	r.queueTime.Record(ctx, m.QueueTime.Seconds(), attrs)
+	r.executionTime.Record(ctx, m.ExecutionTime.Seconds(), attrs)
+	if m.Status == types.StatusStringFinished {
+		r.resultRows.Record(ctx, m.ResultRows, attrs)
+		r.resultSize.Record(ctx, m.ResultSize, attrs)
+	}
+	if m.Status == types.StatusStringFailed || m.Status == types.StatusStringAborted {
+		r.failures.Add(ctx, 1, attrs)
+	}
+}
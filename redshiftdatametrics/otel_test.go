@@ -0,0 +1,93 @@
+package redshiftdatametrics_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
+	redshiftdatasqldriver "github.com/mashiike/redshift-data-sql-driver"
+	"github.com/mashiike/redshift-data-sql-driver/redshiftdatametrics"
+	"github.com/mashiike/redshift-data-sql-driver/redshiftdatamock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestRecorder(t *testing.T) {
+	client := redshiftdatamock.New()
+	client.ExpectExecute(`SELECT * FROM users`).
+		WithStatusTransitions(types.StatusStringSubmitted, types.StatusStringStarted).
+		WillReturnRows(redshiftdatamock.NewRows("id").AddRow(1).AddRow(2))
+	client.ExpectExecute(`SELECT * FROM missing`).
+		WillFail(`relation "missing" does not exist`)
+
+	reader := sdkmetric.NewManualReader()
+	recorder, err := redshiftdatametrics.NewRecorder(
+		sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+		attribute.String("tenant", "a"),
+	)
+	require.NoError(t, err)
+	cfg := (&redshiftdatasqldriver.RedshiftDataConfig{
+		WorkgroupName: aws.String("default"),
+		Database:      aws.String("dev"),
+		Polling:       time.Millisecond,
+	}).WithRedshiftDataClientConstructor(func(_ context.Context, _ *redshiftdatasqldriver.RedshiftDataConfig) (redshiftdatasqldriver.RedshiftDataClient, error) {
+		return client, nil
+	}).WithMetricsRecorder(recorder)
+	db := sql.OpenDB(redshiftdatasqldriver.NewConnector(cfg))
+	defer db.Close()
+
+	rows, err := db.Query(`SELECT * FROM users`)
+	require.NoError(t, err)
+	for rows.Next() {
+	}
+	require.NoError(t, rows.Close())
+	_, err = db.Query(`SELECT * FROM missing`)
+	require.Error(t, err)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	metrics := map[string]metricdata.Aggregation{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+
+	calls := map[string]int64{}
+	for _, dp := range metrics["redshift_data.api.calls"].(metricdata.Sum[int64]).DataPoints {
+		operation, _ := dp.Attributes.Value("operation")
+		tenant, _ := dp.Attributes.Value("tenant")
+		require.Equal(t, "a", tenant.AsString())
+		calls[operation.AsString()] += dp.Value
+	}
+	require.Equal(t, map[string]int64{
+		"ExecuteStatement":   2,
+		"DescribeStatement":  4,
+		"GetStatementResult": 1,
+	}, calls)
+
+	polls := map[string]uint64{}
+	for _, dp := range metrics["redshift_data.statement.polls"].(metricdata.Histogram[int64]).DataPoints {
+		status, _ := dp.Attributes.Value("status")
+		polls[status.AsString()] = uint64(dp.Sum)
+	}
+	require.Equal(t, map[string]uint64{"FINISHED": 3, "FAILED": 1}, polls)
+
+	resultRows := metrics["redshift_data.statement.result_rows"].(metricdata.Histogram[int64]).DataPoints
+	require.Len(t, resultRows, 1)
+	require.Equal(t, int64(2), resultRows[0].Sum)
+
+	failures := metrics["redshift_data.statement.failures"].(metricdata.Sum[int64]).DataPoints
+	require.Len(t, failures, 1)
+	status, _ := failures[0].Attributes.Value("status")
+	require.Equal(t, "FAILED", status.AsString())
+	require.Equal(t, int64(1), failures[0].Value)
+
+	require.Contains(t, metrics, "redshift_data.statement.queue_time")
+	require.Contains(t, metrics, "redshift_data.statement.execution_time")
+}
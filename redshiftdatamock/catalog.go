@@ -0,0 +1,198 @@
+package redshiftdatamock
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
+)
+
+type table struct {
+	database  string
+	schema    string
+	name      string
+	tableType string
+	columns   []types.ColumnMetadata
+}
+
+// AddDatabase registers databases listed by ListDatabases, in order.
+func (c *Client) AddDatabase(names ...string) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, name := range names {
+		c.addDatabase(name)
+	}
+	return c
+}
+
+// AddTable registers a table served by ListSchemas, ListTables and DescribeTable,
+// adding its database and schema as needed. tableType is TABLE or VIEW.
+func (c *Client) AddTable(database, schema, name, tableType string, columns ...types.ColumnMetadata) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.addDatabase(database)
+	c.tables = append(c.tables, &table{
+		database:  database,
+		schema:    schema,
+		name:      name,
+		tableType: tableType,
+		columns:   append([]types.ColumnMetadata{}, columns...),
+	})
+	return c
+}
+
+func (c *Client) addDatabase(name string) {
+	for _, database := range c.databases {
+		if database == name {
+			return
+		}
+	}
+	c.databases = append(c.databases, name)
+}
+
+// ListDatabases lists the registered databases. Targeting is ignored.
+func (c *Client) ListDatabases(ctx context.Context, params *redshiftdata.ListDatabasesInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ListDatabasesOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls[OperationListDatabases]++
+	start, end, next, err := pageRange(len(c.databases), params.NextToken, params.MaxResults)
+	if err != nil {
+		return nil, err
+	}
+	return &redshiftdata.ListDatabasesOutput{
+		Databases: append([]string{}, c.databases[start:end]...),
+		NextToken: next,
+	}, nil
+}
+
+// ListSchemas lists the schemas of the tables registered in Database matching SchemaPattern.
+func (c *Client) ListSchemas(ctx context.Context, params *redshiftdata.ListSchemasInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ListSchemasOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls[OperationListSchemas]++
+	match := likeMatcher(aws.ToString(params.SchemaPattern))
+	var schemas []string
+	seen := make(map[string]bool)
+	for _, t := range c.tables {
+		if t.database != aws.ToString(params.Database) || !match(t.schema) || seen[t.schema] {
+			continue
+		}
+		seen[t.schema] = true
+		schemas = append(schemas, t.schema)
+	}
+	start, end, next, err := pageRange(len(schemas), params.NextToken, params.MaxResults)
+	if err != nil {
+		return nil, err
+	}
+	return &redshiftdata.ListSchemasOutput{
+		Schemas:   schemas[start:end],
+		NextToken: next,
+	}, nil
+}
+
+// ListTables lists the tables registered in Database matching SchemaPattern and TablePattern.
+func (c *Client) ListTables(ctx context.Context, params *redshiftdata.ListTablesInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ListTablesOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls[OperationListTables]++
+	matchSchema := likeMatcher(aws.ToString(params.SchemaPattern))
+	matchTable := likeMatcher(aws.ToString(params.TablePattern))
+	var tables []types.TableMember
+	for _, t := range c.tables {
+		if t.database != aws.ToString(params.Database) || !matchSchema(t.schema) || !matchTable(t.name) {
+			continue
+		}
+		tables = append(tables, types.TableMember{
+			Schema: aws.String(t.schema),
+			Name:   aws.String(t.name),
+			Type:   aws.String(t.tableType),
+		})
+	}
+	start, end, next, err := pageRange(len(tables), params.NextToken, params.MaxResults)
+	if err != nil {
+		return nil, err
+	}
+	return &redshiftdata.ListTablesOutput{
+		Tables:    tables[start:end],
+		NextToken: next,
+	}, nil
+}
+
+// DescribeTable returns the columns of the registered table, or none if there is no such table.
+// Schema defaults to public, as in the Data API.
+func (c *Client) DescribeTable(ctx context.Context, params *redshiftdata.DescribeTableInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.DescribeTableOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls[OperationDescribeTable]++
+	schema := aws.ToString(params.Schema)
+	if schema == "" {
+		schema = "public"
+	}
+	var columns []types.ColumnMetadata
+	for _, t := range c.tables {
+		if t.database == aws.ToString(params.Database) && t.schema == schema && t.name == aws.ToString(params.Table) {
+			columns = t.columns
+			break
+		}
+	}
+	start, end, next, err := pageRange(len(columns), params.NextToken, params.MaxResults)
+	if err != nil {
+		return nil, err
+	}
+	return &redshiftdata.DescribeTableOutput{
+		TableName:  params.Table,
+		ColumnList: columns[start:end],
+		NextToken:  next,
+	}, nil
+}
+
+// pageRange returns the part of n items a page starting at the offset token covers,
+// and the token of the next page.
+func pageRange(n int, token *string, maxResults int32) (int, int, *string, error) {
+	start := 0
+	if token != nil {
+		var err error
+		start, err = strconv.Atoi(*token)
+		if err != nil || start > n {
+			return 0, 0, nil, &types.ValidationException{
+				Message: aws.String(fmt.Sprintf("invalid next token: %s", *token)),
+			}
+		}
+	}
+	end := n
+	if maxResults > 0 && start+int(maxResults) < end {
+		end = start + int(maxResults)
+	}
+	var next *string
+	if end < n {
+		next = aws.String(strconv.Itoa(end))
+	}
+	return start, end, next, nil
+}
+
+// likeMatcher reports whether a name matches the LIKE pattern; an empty pattern matches every name.
+func likeMatcher(pattern string) func(string) bool {
+	if pattern == "" {
+		return func(string) bool { return true }
+	}
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '%':
+			b.WriteString(".*")
+		case c == '_':
+			b.WriteString(".")
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	return regexp.MustCompile("(?s)^" + b.String() + "$").MatchString
+}
@@ -0,0 +1,478 @@
+// Package redshiftdatamock provides a scriptable Redshift Data API client for tests.
+//
+// Client satisfies redshiftdatasqldriver.RedshiftDataClient, so it can be returned from
+// RedshiftDataClientConstructor to run code that uses the driver without AWS credentials.
+package redshiftdatamock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
+)
+
+// Operation names accepted by Client.CallCount.
+const (
+	OperationExecuteStatement      = "ExecuteStatement"
+	OperationBatchExecuteStatement = "BatchExecuteStatement"
+	OperationDescribeStatement     = "DescribeStatement"
+	OperationCancelStatement       = "CancelStatement"
+	OperationGetStatementResult    = "GetStatementResult"
+	OperationGetStatementResultV2  = "GetStatementResultV2"
+	OperationListStatements        = "ListStatements"
+	OperationListDatabases         = "ListDatabases"
+	OperationListSchemas           = "ListSchemas"
+	OperationListTables            = "ListTables"
+	OperationDescribeTable         = "DescribeTable"
+)
+
+// Client is an in-memory Redshift Data API that serves scripted expectations.
+type Client struct {
+	mu           sync.Mutex
+	expectations []*Expectation
+	statements   map[string]*statement
+	calls        map[string]int
+	seq          int64
+	databases    []string
+	tables       []*table
+}
+
+type statement struct {
+	id            string
+	name          string
+	exp           *Expectation
+	sql           string
+	sqls          []string
+	params        []types.SqlParameter
+	sessionID     *string
+	resultFormat  types.ResultFormatString
+	queryID       int64
+	createdAt     time.Time
+	describeCalls int
+	cancelled     bool
+}
+
+// New returns a Client without expectations.
+func New() *Client {
+	return &Client{
+		statements: make(map[string]*statement),
+		calls:      make(map[string]int),
+	}
+}
+
+// ExpectExecute registers an ExecuteStatement expectation.
+// The SQL is compared after collapsing whitespace.
+func (c *Client) ExpectExecute(sql string) *Expectation {
+	e := newExpectation()
+	e.sql = sql
+	return c.expect(e)
+}
+
+// ExpectExecuteMatch registers an ExecuteStatement expectation whose SQL matches the pattern.
+func (c *Client) ExpectExecuteMatch(pattern string) *Expectation {
+	e := newExpectation()
+	e.sqlRe = regexp.MustCompile(pattern)
+	return c.expect(e)
+}
+
+// ExpectBatchExecute registers a BatchExecuteStatement expectation for the given SQLs.
+func (c *Client) ExpectBatchExecute(sqls ...string) *Expectation {
+	e := newExpectation()
+	e.batch = true
+	e.sqls = append([]string{}, sqls...)
+	return c.expect(e)
+}
+
+func (c *Client) expect(e *Expectation) *Expectation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expectations = append(c.expectations, e)
+	return e
+}
+
+// CallCount reports how many times the operation has been called.
+func (c *Client) CallCount(operation string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls[operation]
+}
+
+// ExpectationsWereMet returns an error describing every expectation that was called
+// fewer times than required.
+func (c *Client) ExpectationsWereMet() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var msgs []string
+	for _, e := range c.expectations {
+		if !e.satisfied() {
+			msgs = append(msgs, fmt.Sprintf("expected %s to be called %d times, but called %d times", e, e.times, e.matched))
+		}
+	}
+	if len(msgs) > 0 {
+		return errors.New("redshiftdatamock: " + strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+func (c *Client) ExecuteStatement(ctx context.Context, params *redshiftdata.ExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ExecuteStatementOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls[OperationExecuteStatement]++
+	sql := aws.ToString(params.Sql)
+	for _, e := range c.expectations {
+		if e.exhausted() || !e.matchExecute(sql, params.Parameters) {
+			continue
+		}
+		e.matched++
+		if e.submitErr != nil {
+			return nil, e.submitErr
+		}
+		st := c.newStatement(e)
+		st.name = aws.ToString(params.StatementName)
+		st.sql = sql
+		st.params = params.Parameters
+		st.sessionID = params.SessionId
+		st.resultFormat = params.ResultFormat
+		if st.sessionID == nil && params.SessionKeepAliveSeconds != nil {
+			st.sessionID = aws.String(fmt.Sprintf("mock-session-%08d", c.seq))
+		}
+		return &redshiftdata.ExecuteStatementOutput{
+			Id:                aws.String(st.id),
+			CreatedAt:         aws.Time(st.createdAt),
+			ClusterIdentifier: params.ClusterIdentifier,
+			Database:          params.Database,
+			DbUser:            params.DbUser,
+			SecretArn:         params.SecretArn,
+			WorkgroupName:     params.WorkgroupName,
+			SessionId:         st.sessionID,
+		}, nil
+	}
+	return nil, fmt.Errorf("redshiftdatamock: unexpected ExecuteStatement: sql=%q parameters=%s", sql, formatParameters(params.Parameters))
+}
+
+func (c *Client) BatchExecuteStatement(ctx context.Context, params *redshiftdata.BatchExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.BatchExecuteStatementOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls[OperationBatchExecuteStatement]++
+	for _, e := range c.expectations {
+		if e.exhausted() || !e.matchBatch(params.Sqls) {
+			continue
+		}
+		e.matched++
+		if e.submitErr != nil {
+			return nil, e.submitErr
+		}
+		st := c.newStatement(e)
+		st.name = aws.ToString(params.StatementName)
+		st.sqls = append([]string{}, params.Sqls...)
+		return &redshiftdata.BatchExecuteStatementOutput{
+			Id:                aws.String(st.id),
+			CreatedAt:         aws.Time(st.createdAt),
+			ClusterIdentifier: params.ClusterIdentifier,
+			Database:          params.Database,
+			DbUser:            params.DbUser,
+			SecretArn:         params.SecretArn,
+			WorkgroupName:     params.WorkgroupName,
+		}, nil
+	}
+	return nil, fmt.Errorf("redshiftdatamock: unexpected BatchExecuteStatement: sqls=%q", params.Sqls)
+}
+
+func (c *Client) newStatement(e *Expectation) *statement {
+	c.seq++
+	st := &statement{
+		id:        fmt.Sprintf("mock-statement-%08d", c.seq),
+		exp:       e,
+		queryID:   c.seq,
+		createdAt: time.Now(),
+	}
+	c.statements[st.id] = st
+	return st
+}
+
+func (c *Client) DescribeStatement(ctx context.Context, params *redshiftdata.DescribeStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.DescribeStatementOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls[OperationDescribeStatement]++
+	st, err := c.lookup(params.Id)
+	if err != nil {
+		return nil, err
+	}
+	st.describeCalls++
+	now := time.Now()
+	status := st.status(now)
+	output := &redshiftdata.DescribeStatementOutput{
+		Id:              aws.String(st.id),
+		Status:          status,
+		CreatedAt:       aws.Time(st.createdAt),
+		UpdatedAt:       aws.Time(now),
+		RedshiftQueryId: st.queryID,
+		RedshiftPid:     1073741824 + st.queryID,
+		HasResultSet:    aws.Bool(false),
+		QueryParameters: st.params,
+		SessionId:       st.sessionID,
+		ResultFormat:    st.resultFormat,
+	}
+	if st.exp.batch {
+		output.SubStatements = st.subStatements(status)
+	} else {
+		output.QueryString = aws.String(st.sql)
+	}
+	switch status {
+	case types.StatusStringFinished:
+		output.Duration = now.Sub(st.createdAt).Nanoseconds()
+		if st.exp.rows != nil {
+			output.HasResultSet = aws.Bool(true)
+			output.ResultRows = st.exp.rows.len()
+			output.ResultSize = st.exp.rows.size()
+		} else if !st.exp.batch && len(st.exp.rowsAffected) > 0 {
+			output.ResultRows = st.exp.rowsAffected[0]
+		}
+	case types.StatusStringFailed, types.StatusStringAborted:
+		output.Error = aws.String(st.errorMessage())
+	}
+	return output, nil
+}
+
+func (c *Client) CancelStatement(ctx context.Context, params *redshiftdata.CancelStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.CancelStatementOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls[OperationCancelStatement]++
+	st, err := c.lookup(params.Id)
+	if err != nil {
+		return nil, err
+	}
+	switch status := st.status(time.Now()); status {
+	case types.StatusStringFinished, types.StatusStringFailed, types.StatusStringAborted:
+		return nil, &types.ValidationException{
+			Message: aws.String(fmt.Sprintf("Could not cancel a query that is already in %s state with ID: %s", status, st.id)),
+		}
+	}
+	st.cancelled = true
+	return &redshiftdata.CancelStatementOutput{
+		Status: aws.Bool(true),
+	}, nil
+}
+
+func (c *Client) GetStatementResult(ctx context.Context, params *redshiftdata.GetStatementResultInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.GetStatementResultOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls[OperationGetStatementResult]++
+	st, index, err := c.result(params.Id, params.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	if st.resultFormat == types.ResultFormatStringCsv {
+		return nil, &types.ValidationException{
+			Message: aws.String("Query result is in CSV format. Use GetStatementResultV2 API."),
+		}
+	}
+	records, hasMore := st.exp.rows.page(index)
+	output := &redshiftdata.GetStatementResultOutput{
+		ColumnMetadata: st.exp.rows.columnMetadata(),
+		Records:        records,
+		TotalNumRows:   st.exp.rows.len(),
+	}
+	if hasMore {
+		output.NextToken = aws.String(strconv.Itoa(index + 1))
+	}
+	return output, nil
+}
+
+// GetStatementResultV2 serves the result of a statement run with the CSV result format.
+// As in the Data API, the first page starts with a header line of the column names,
+// and NULL is written as an empty value.
+func (c *Client) GetStatementResultV2(ctx context.Context, params *redshiftdata.GetStatementResultV2Input, optFns ...func(*redshiftdata.Options)) (*redshiftdata.GetStatementResultV2Output, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls[OperationGetStatementResultV2]++
+	st, index, err := c.result(params.Id, params.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	if st.resultFormat != types.ResultFormatStringCsv {
+		return nil, &types.ValidationException{
+			Message: aws.String("Query result is in JSON format. Use GetStatementResult API."),
+		}
+	}
+	records, hasMore := st.exp.rows.page(index)
+	csvRecords, err := st.exp.rows.csv(records, index == 0)
+	if err != nil {
+		return nil, err
+	}
+	output := &redshiftdata.GetStatementResultV2Output{
+		ColumnMetadata: st.exp.rows.columnMetadata(),
+		Records:        []types.QueryRecords{&types.QueryRecordsMemberCSVRecords{Value: csvRecords}},
+		ResultFormat:   types.ResultFormatStringCsv,
+		TotalNumRows:   st.exp.rows.len(),
+	}
+	if hasMore {
+		output.NextToken = aws.String(strconv.Itoa(index + 1))
+	}
+	return output, nil
+}
+
+// result looks up a finished statement with a result set and the page index of token.
+func (c *Client) result(id *string, token *string) (*statement, int, error) {
+	st, err := c.lookup(id)
+	if err != nil {
+		return nil, 0, err
+	}
+	if status := st.status(time.Now()); status != types.StatusStringFinished {
+		return nil, 0, &types.ValidationException{
+			Message: aws.String(fmt.Sprintf("Query has not finished yet, status: %s", status)),
+		}
+	}
+	if st.exp.rows == nil {
+		return nil, 0, &types.ResourceNotFoundException{
+			Message: aws.String(fmt.Sprintf("Query does not have result. Please check query status with DescribeStatement API: %s", st.id)),
+		}
+	}
+	index := 0
+	if token != nil {
+		index, err = strconv.Atoi(*token)
+		if err != nil {
+			return nil, 0, &types.ValidationException{
+				Message: aws.String(fmt.Sprintf("invalid next token: %s", *token)),
+			}
+		}
+	}
+	return st, index, nil
+}
+
+// ListStatements lists the statements submitted to the Client, most recent first,
+// filtered by Status and StatementName prefix. Targeting and RoleLevel are ignored.
+func (c *Client) ListStatements(ctx context.Context, params *redshiftdata.ListStatementsInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ListStatementsOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls[OperationListStatements]++
+	now := time.Now()
+	var matched []*statement
+	for _, st := range c.statements {
+		if params.Status != "" && params.Status != types.StatusStringAll && st.status(now) != params.Status {
+			continue
+		}
+		if !strings.HasPrefix(st.name, aws.ToString(params.StatementName)) {
+			continue
+		}
+		matched = append(matched, st)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].queryID > matched[j].queryID
+	})
+	index := 0
+	if params.NextToken != nil {
+		var err error
+		index, err = strconv.Atoi(*params.NextToken)
+		if err != nil || index > len(matched) {
+			return nil, &types.ValidationException{
+				Message: aws.String(fmt.Sprintf("invalid next token: %s", *params.NextToken)),
+			}
+		}
+	}
+	end := len(matched)
+	if params.MaxResults > 0 && index+int(params.MaxResults) < end {
+		end = index + int(params.MaxResults)
+	}
+	output := &redshiftdata.ListStatementsOutput{}
+	for _, st := range matched[index:end] {
+		data := types.StatementData{
+			Id:               aws.String(st.id),
+			Status:           st.status(now),
+			CreatedAt:        aws.Time(st.createdAt),
+			UpdatedAt:        aws.Time(now),
+			IsBatchStatement: aws.Bool(st.exp.batch),
+			QueryParameters:  st.params,
+			SessionId:        st.sessionID,
+		}
+		if st.name != "" {
+			data.StatementName = aws.String(st.name)
+		}
+		if st.exp.batch {
+			data.QueryStrings = st.sqls
+		} else {
+			data.QueryString = aws.String(st.sql)
+		}
+		output.Statements = append(output.Statements, data)
+	}
+	if end < len(matched) {
+		output.NextToken = aws.String(strconv.Itoa(end))
+	}
+	return output, nil
+}
+
+func (c *Client) lookup(id *string) (*statement, error) {
+	st, ok := c.statements[aws.ToString(id)]
+	if !ok {
+		return nil, &types.ResourceNotFoundException{
+			Message:    aws.String(fmt.Sprintf("Query does not exist: %s", aws.ToString(id))),
+			ResourceId: id,
+		}
+	}
+	return st, nil
+}
+
+func (st *statement) status(now time.Time) types.StatusString {
+	if st.cancelled {
+		return types.StatusStringAborted
+	}
+	if st.describeCalls > 0 && st.describeCalls <= len(st.exp.transitions) {
+		return st.exp.transitions[st.describeCalls-1]
+	}
+	if now.Sub(st.createdAt) < st.exp.latency {
+		return types.StatusStringStarted
+	}
+	return st.exp.finalStatus
+}
+
+func (st *statement) errorMessage() string {
+	if st.cancelled {
+		return "Query cancelled by user."
+	}
+	return st.exp.errorMessage
+}
+
+func (st *statement) subStatements(status types.StatusString) []types.SubStatementData {
+	subs := make([]types.SubStatementData, 0, len(st.sqls))
+	for i, sql := range st.sqls {
+		sub := types.SubStatementData{
+			Id:              aws.String(fmt.Sprintf("%s:%d", st.id, i+1)),
+			Status:          types.StatementStatusString(status),
+			QueryString:     aws.String(sql),
+			HasResultSet:    aws.Bool(false),
+			RedshiftQueryId: st.queryID,
+			CreatedAt:       aws.Time(st.createdAt),
+		}
+		if status == types.StatusStringFinished && i < len(st.exp.rowsAffected) {
+			sub.ResultRows = st.exp.rowsAffected[i]
+		}
+		if status == types.StatusStringFailed || status == types.StatusStringAborted {
+			sub.Error = aws.String(st.errorMessage())
+		}
+		subs = append(subs, sub)
+	}
+	return subs
+}
+
+func formatParameters(params []types.SqlParameter) string {
+	if len(params) == 0 {
+		return "[]"
+	}
+	s := "["
+	for i, p := range params {
+		if i > 0 {
+			s += " "
+		}
+		s += aws.ToString(p.Name) + "=" + strconv.Quote(aws.ToString(p.Value))
+	}
+	return s + "]"
+}
@@ -0,0 +1,263 @@
+package redshiftdatamock_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
+	redshiftdatasqldriver "github.com/mashiike/redshift-data-sql-driver"
+	"github.com/mashiike/redshift-data-sql-driver/redshiftdatamock"
+	"github.com/stretchr/testify/require"
+)
+
+var mockClients = map[string]*redshiftdatamock.Client{}
+
+func init() {
+	redshiftdatasqldriver.RedshiftDataClientConstructor = func(ctx context.Context, cfg *redshiftdatasqldriver.RedshiftDataConfig) (redshiftdatasqldriver.RedshiftDataClient, error) {
+		client, ok := mockClients[cfg.Params.Get("mock")]
+		if !ok {
+			return nil, fmt.Errorf("mock client %q not found", cfg.Params.Get("mock"))
+		}
+		return client, nil
+	}
+}
+
+func openDB(t *testing.T, client *redshiftdatamock.Client) *sql.DB {
+	t.Helper()
+	mockClients[t.Name()] = client
+	dsn := (&redshiftdatasqldriver.RedshiftDataConfig{
+		WorkgroupName: aws.String("default"),
+		Database:      aws.String("dev"),
+		Polling:       time.Millisecond,
+		Params:        url.Values{"mock": []string{t.Name()}},
+	}).String()
+	db, err := sql.Open("redshift-data", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.Close()
+		delete(mockClients, t.Name())
+	})
+	return db
+}
+
+func TestClientQuery(t *testing.T) {
+	client := redshiftdatamock.New()
+	client.ExpectExecute(`SELECT id, name, created_at FROM users WHERE age > :1`).
+		WithArgs(20).
+		WithStatusTransitions(types.StatusStringSubmitted, types.StatusStringPicked, types.StatusStringStarted).
+		WillReturnRows(
+			redshiftdatamock.NewRows("id", "name", "created_at").
+				AddRow(1, "hoge", time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)).
+				AddRow(2, nil, time.Date(2023, 2, 3, 4, 5, 6, 0, time.UTC)).
+				AddRow(3, "piyo", nil).
+				WithPageSize(2),
+		)
+	db := openDB(t, client)
+
+	rows, err := db.QueryContext(context.Background(), `SELECT id, name, created_at FROM users WHERE age > ?`, 20)
+	require.NoError(t, err)
+	defer rows.Close()
+	type user struct {
+		ID        int64
+		Name      sql.NullString
+		CreatedAt sql.NullTime
+	}
+	var actual []user
+	for rows.Next() {
+		var u user
+		require.NoError(t, rows.Scan(&u.ID, &u.Name, &u.CreatedAt))
+		actual = append(actual, u)
+	}
+	require.NoError(t, rows.Err())
+	require.Equal(t, []user{
+		{ID: 1, Name: sql.NullString{String: "hoge", Valid: true}, CreatedAt: sql.NullTime{Time: time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC), Valid: true}},
+		{ID: 2, CreatedAt: sql.NullTime{Time: time.Date(2023, 2, 3, 4, 5, 6, 0, time.UTC), Valid: true}},
+		{ID: 3, Name: sql.NullString{String: "piyo", Valid: true}},
+	}, actual)
+	require.NoError(t, client.ExpectationsWereMet())
+	require.Equal(t, 1, client.CallCount(redshiftdatamock.OperationExecuteStatement))
+	require.Equal(t, 4, client.CallCount(redshiftdatamock.OperationDescribeStatement))
+	require.Equal(t, 2, client.CallCount(redshiftdatamock.OperationGetStatementResult))
+}
+
+func TestClientExec(t *testing.T) {
+	client := redshiftdatamock.New()
+	client.ExpectExecute(`DELETE FROM users WHERE name = :name`).
+		WithArgs(sql.Named("name", "hoge")).
+		WillReturnResult(3)
+	db := openDB(t, client)
+
+	result, err := db.ExecContext(context.Background(), `DELETE FROM users WHERE name = :name`, sql.Named("name", "hoge"))
+	require.NoError(t, err)
+	rowsAffected, err := result.RowsAffected()
+	require.NoError(t, err)
+	require.Equal(t, int64(3), rowsAffected)
+
+	_, err = db.ExecContext(context.Background(), `DELETE FROM users WHERE name = :name`, sql.Named("name", "fuga"))
+	require.ErrorContains(t, err, "unexpected ExecuteStatement")
+	require.NoError(t, client.ExpectationsWereMet())
+}
+
+func TestClientFail(t *testing.T) {
+	client := redshiftdatamock.New()
+	client.ExpectExecuteMatch(`^SELECT \* FROM missing`).
+		WillFail(`relation "missing" does not exist`)
+	db := openDB(t, client)
+
+	_, err := db.QueryContext(context.Background(), `SELECT * FROM missing`)
+	require.ErrorContains(t, err, `query failed: relation "missing" does not exist`)
+}
+
+func TestClientLatencyAndCancel(t *testing.T) {
+	client := redshiftdatamock.New()
+	client.ExpectExecute(`SELECT * FROM long_long_view`).
+		WithLatency(time.Hour).
+		WillReturnResult(0)
+	db := openDB(t, client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := db.ExecContext(ctx, `SELECT * FROM long_long_view`)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, 1, client.CallCount(redshiftdatamock.OperationCancelStatement))
+}
+
+func TestClientBatch(t *testing.T) {
+	client := redshiftdatamock.New()
+	client.ExpectBatchExecute(
+		`INSERT INTO users VALUES (1, 'hoge')`,
+		`INSERT INTO users VALUES (2, 'fuga')`,
+	).WillReturnResult(1, 1)
+	db := openDB(t, client)
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	_, err = tx.Exec(`INSERT INTO users VALUES (1, 'hoge')`)
+	require.NoError(t, err)
+	_, err = tx.Exec(`INSERT INTO users VALUES (2, 'fuga')`)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+	require.NoError(t, client.ExpectationsWereMet())
+}
+
+func TestClientExpectationsWereMet(t *testing.T) {
+	client := redshiftdatamock.New()
+	client.ExpectExecute(`SELECT 1`).Times(2).WillReturnRows(redshiftdatamock.NewRows("?column?").AddRow(1))
+	client.ExpectExecute(`SELECT 2`).AnyTimes()
+	db := openDB(t, client)
+
+	var n int64
+	require.NoError(t, db.QueryRow(`SELECT 1`).Scan(&n))
+	require.Equal(t, int64(1), n)
+	require.ErrorContains(t, client.ExpectationsWereMet(), "called 2 times, but called 1 times")
+	require.NoError(t, db.QueryRow(`SELECT 1`).Scan(&n))
+	require.NoError(t, client.ExpectationsWereMet())
+}
+
+func TestClientListStatements(t *testing.T) {
+	client := redshiftdatamock.New()
+	client.ExpectExecute(`VACUUM users`).WithLatency(time.Hour)
+	client.ExpectExecute(`SELECT 1`).WillReturnRows(redshiftdatamock.NewRows("?column?").AddRow(1))
+	db := openDB(t, client)
+
+	ctx := context.Background()
+	_, err := client.ExecuteStatement(ctx, &redshiftdata.ExecuteStatementInput{
+		Sql:           aws.String(`VACUUM users`),
+		StatementName: aws.String("worker-1"),
+	})
+	require.NoError(t, err)
+	var n int64
+	require.NoError(t, db.QueryRowContext(redshiftdatasqldriver.WithStatementName(ctx, "worker-2"), `SELECT 1`).Scan(&n))
+
+	history, err := redshiftdatasqldriver.NewStatementHistory(ctx, db)
+	require.NoError(t, err)
+	all, err := history.List(ctx, &redshiftdatasqldriver.ListStatementsOptions{StatementName: "worker-", PageSize: 1})
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	require.Equal(t, "worker-2", all[0].Name)
+	require.Equal(t, "worker-1", all[1].Name)
+
+	running, err := history.List(ctx, &redshiftdatasqldriver.ListStatementsOptions{Status: types.StatusStringStarted})
+	require.NoError(t, err)
+	require.Len(t, running, 1)
+	require.Equal(t, []string{`VACUUM users`}, running[0].Sqls)
+	require.NoError(t, history.Cancel(ctx, running[0].ID))
+	desc, err := history.Wait(ctx, running[0].ID)
+	require.ErrorContains(t, err, "query aborted")
+	require.Equal(t, types.StatusStringAborted, desc.Status)
+	require.Equal(t, 3, client.CallCount(redshiftdatamock.OperationListStatements))
+}
+
+func TestClientCatalog(t *testing.T) {
+	client := redshiftdatamock.New().
+		AddTable("dev", "public", "users", "TABLE",
+			types.ColumnMetadata{Name: aws.String("id"), TypeName: aws.String("int8")},
+		).
+		AddTable("dev", "public", "active_users", "VIEW").
+		AddTable("dev", "staging", "users_load", "TABLE").
+		AddDatabase("analytics")
+	db := openDB(t, client)
+
+	ctx := context.Background()
+	catalog, err := redshiftdatasqldriver.NewCatalog(ctx, db)
+	require.NoError(t, err)
+	databases, err := catalog.Databases(ctx, &redshiftdatasqldriver.CatalogOptions{PageSize: 1})
+	require.NoError(t, err)
+	require.Equal(t, []string{"dev", "analytics"}, databases)
+	schemas, err := catalog.Schemas(ctx, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"public", "staging"}, schemas)
+	tables, err := catalog.Tables(ctx, &redshiftdatasqldriver.CatalogOptions{TablePattern: "%users"})
+	require.NoError(t, err)
+	require.Equal(t, []redshiftdatasqldriver.Table{
+		{Schema: "public", Name: "users", Type: "TABLE"},
+		{Schema: "public", Name: "active_users", Type: "VIEW"},
+	}, tables)
+	desc, err := catalog.DescribeTable(ctx, "public", "users", nil)
+	require.NoError(t, err)
+	require.Equal(t, []redshiftdatasqldriver.Column{{Name: "id", TypeName: "int8"}}, desc.Columns)
+	desc, err = catalog.DescribeTable(ctx, "public", "missing", nil)
+	require.NoError(t, err)
+	require.Empty(t, desc.Columns)
+	require.Equal(t, 2, client.CallCount(redshiftdatamock.OperationListDatabases))
+	require.Equal(t, 2, client.CallCount(redshiftdatamock.OperationDescribeTable))
+}
+
+func TestClientCSVResult(t *testing.T) {
+	client := redshiftdatamock.New()
+	client.ExpectExecute(`SELECT id, name FROM users`).
+		WillReturnRows(redshiftdatamock.NewRows("id", "name").WithPageSize(1).AddRow(1, "hoge").AddRow(2, nil)).
+		Times(2)
+	db := openDB(t, client)
+
+	ctx := redshiftdatasqldriver.WithResultFormat(context.Background(), types.ResultFormatStringCsv)
+	rows, err := db.QueryContext(ctx, `SELECT id, name FROM users`)
+	require.NoError(t, err)
+	defer rows.Close()
+	var names []sql.NullString
+	for rows.Next() {
+		var id int64
+		var name sql.NullString
+		require.NoError(t, rows.Scan(&id, &name))
+		names = append(names, name)
+	}
+	require.NoError(t, rows.Err())
+	require.Equal(t, []sql.NullString{{String: "hoge", Valid: true}, {}}, names)
+	require.Equal(t, 2, client.CallCount(redshiftdatamock.OperationGetStatementResultV2))
+
+	output, err := client.ExecuteStatement(context.Background(), &redshiftdata.ExecuteStatementInput{
+		Sql:          aws.String(`SELECT id, name FROM users`),
+		ResultFormat: types.ResultFormatStringCsv,
+	})
+	require.NoError(t, err)
+	_, err = client.GetStatementResult(context.Background(), &redshiftdata.GetStatementResultInput{Id: output.Id})
+	var validation *types.ValidationException
+	require.ErrorAs(t, err, &validation)
+	require.NoError(t, client.ExpectationsWereMet())
+}
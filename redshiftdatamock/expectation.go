@@ -0,0 +1,200 @@
+package redshiftdatamock
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
+)
+
+// Expectation describes one scripted ExecuteStatement or BatchExecuteStatement call.
+type Expectation struct {
+	batch   bool
+	sql     string
+	sqlRe   *regexp.Regexp
+	sqls    []string
+	params  []types.SqlParameter
+	anyArgs bool
+
+	rows         *Rows
+	rowsAffected []int64
+	submitErr    error
+	finalStatus  types.StatusString
+	errorMessage string
+	latency      time.Duration
+	transitions  []types.StatusString
+
+	times   int
+	matched int
+}
+
+func newExpectation() *Expectation {
+	return &Expectation{
+		anyArgs:     true,
+		finalStatus: types.StatusStringFinished,
+		times:       1,
+	}
+}
+
+// WithParameters restricts the expectation to calls with exactly these parameters.
+func (e *Expectation) WithParameters(params ...types.SqlParameter) *Expectation {
+	e.params = append([]types.SqlParameter{}, params...)
+	e.anyArgs = false
+	return e
+}
+
+// WithArgs restricts the expectation to calls whose parameters were built from args.
+// sql.NamedArg values keep their name, other values are numbered in order among themselves,
+// in the same way the driver converts query arguments.
+func (e *Expectation) WithArgs(args ...any) *Expectation {
+	params := make([]types.SqlParameter, 0, len(args))
+	var positional int
+	for _, arg := range args {
+		var name string
+		if named, ok := arg.(sql.NamedArg); ok {
+			name = named.Name
+			arg = named.Value
+		} else {
+			positional++
+			name = fmt.Sprintf("%d", positional)
+		}
+		params = append(params, types.SqlParameter{
+			Name:  aws.String(name),
+			Value: aws.String(fmt.Sprintf("%v", arg)),
+		})
+	}
+	return e.WithParameters(params...)
+}
+
+// WithoutArgs restricts the expectation to calls without parameters.
+func (e *Expectation) WithoutArgs() *Expectation {
+	return e.WithParameters()
+}
+
+// WillReturnRows makes the statement finish with the given result set.
+func (e *Expectation) WillReturnRows(rows *Rows) *Expectation {
+	e.rows = rows
+	return e
+}
+
+// WillReturnResult makes the statement finish without a result set.
+// For a batch, one value per sub statement is expected.
+func (e *Expectation) WillReturnResult(rowsAffected ...int64) *Expectation {
+	e.rowsAffected = append([]int64{}, rowsAffected...)
+	return e
+}
+
+// WillReturnError makes the submit call itself fail with err.
+func (e *Expectation) WillReturnError(err error) *Expectation {
+	e.submitErr = err
+	return e
+}
+
+// WillFail makes the statement end in FAILED status with the given error message.
+func (e *Expectation) WillFail(message string) *Expectation {
+	e.finalStatus = types.StatusStringFailed
+	e.errorMessage = message
+	return e
+}
+
+// WillAbort makes the statement end in ABORTED status.
+func (e *Expectation) WillAbort(message string) *Expectation {
+	e.finalStatus = types.StatusStringAborted
+	e.errorMessage = message
+	return e
+}
+
+// WithLatency keeps the statement in STARTED status until d has passed since submission.
+func (e *Expectation) WithLatency(d time.Duration) *Expectation {
+	e.latency = d
+	return e
+}
+
+// WithStatusTransitions makes successive DescribeStatement calls report the given
+// statuses before the final one.
+func (e *Expectation) WithStatusTransitions(statuses ...types.StatusString) *Expectation {
+	e.transitions = append([]types.StatusString{}, statuses...)
+	return e
+}
+
+// Times sets how many calls the expectation serves. The default is 1.
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+// AnyTimes lets the expectation serve any number of calls, including none.
+func (e *Expectation) AnyTimes() *Expectation {
+	e.times = -1
+	return e
+}
+
+func (e *Expectation) exhausted() bool {
+	return e.times >= 0 && e.matched >= e.times
+}
+
+func (e *Expectation) satisfied() bool {
+	return e.times < 0 || e.matched >= e.times
+}
+
+func (e *Expectation) String() string {
+	if e.batch {
+		return fmt.Sprintf("BatchExecuteStatement %q", e.sqls)
+	}
+	if e.sqlRe != nil {
+		return fmt.Sprintf("ExecuteStatement matching %q", e.sqlRe.String())
+	}
+	return fmt.Sprintf("ExecuteStatement %q", e.sql)
+}
+
+func (e *Expectation) matchExecute(sql string, params []types.SqlParameter) bool {
+	if e.batch {
+		return false
+	}
+	if e.sqlRe != nil {
+		if !e.sqlRe.MatchString(sql) {
+			return false
+		}
+	} else if normalizeSQL(e.sql) != normalizeSQL(sql) {
+		return false
+	}
+	return e.matchParameters(params)
+}
+
+func (e *Expectation) matchBatch(sqls []string) bool {
+	if !e.batch || len(e.sqls) != len(sqls) {
+		return false
+	}
+	for i := range sqls {
+		if normalizeSQL(e.sqls[i]) != normalizeSQL(sqls[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *Expectation) matchParameters(params []types.SqlParameter) bool {
+	if e.anyArgs {
+		return true
+	}
+	if len(e.params) != len(params) {
+		return false
+	}
+	for i := range params {
+		if aws.ToString(e.params[i].Name) != aws.ToString(params[i].Name) {
+			return false
+		}
+		if aws.ToString(e.params[i].Value) != aws.ToString(params[i].Value) {
+			return false
+		}
+	}
+	return true
+}
+
+func normalizeSQL(sql string) string {
+	return strings.Join(strings.Fields(sql), " ")
+}
@@ -0,0 +1,219 @@
+package redshiftdatamock
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
+)
+
+// Rows is a canned result set served through GetStatementResult.
+type Rows struct {
+	names     []string
+	typeNames []string
+	notNull   []bool
+	records   [][]types.Field
+	pageSize  int
+}
+
+// NewRows returns an empty result set with the given column names.
+// Column types are inferred from the first row unless WithColumnTypes is used.
+func NewRows(columns ...string) *Rows {
+	return &Rows{
+		names:     append([]string{}, columns...),
+		typeNames: make([]string, len(columns)),
+		notNull:   make([]bool, len(columns)),
+	}
+}
+
+// WithColumnTypes sets the Redshift type names reported in the column metadata.
+func (r *Rows) WithColumnTypes(typeNames ...string) *Rows {
+	for i, typeName := range typeNames {
+		if i < len(r.typeNames) {
+			r.typeNames[i] = typeName
+		}
+	}
+	return r
+}
+
+// WithNotNull marks the named columns as not nullable in the column metadata.
+func (r *Rows) WithNotNull(columns ...string) *Rows {
+	for _, column := range columns {
+		for i, name := range r.names {
+			if name == column {
+				r.notNull[i] = true
+			}
+		}
+	}
+	return r
+}
+
+// WithPageSize splits the records into pages of n rows.
+func (r *Rows) WithPageSize(n int) *Rows {
+	r.pageSize = n
+	return r
+}
+
+// AddRow appends a record built from Go values.
+// nil, string, bool, integer, float, []byte and time.Time values are supported;
+// anything else is sent as its fmt.Sprint representation.
+func (r *Rows) AddRow(values ...any) *Rows {
+	record := make([]types.Field, len(r.names))
+	for i := range record {
+		var value any
+		if i < len(values) {
+			value = values[i]
+		}
+		field, typeName := toField(value)
+		record[i] = field
+		if r.typeNames[i] == "" && value != nil {
+			r.typeNames[i] = typeName
+		}
+	}
+	r.records = append(r.records, record)
+	return r
+}
+
+func (r *Rows) len() int64 {
+	if r == nil {
+		return 0
+	}
+	return int64(len(r.records))
+}
+
+func (r *Rows) size() int64 {
+	if r == nil {
+		return 0
+	}
+	var size int64
+	for _, record := range r.records {
+		for _, field := range record {
+			switch f := field.(type) {
+			case *types.FieldMemberStringValue:
+				size += int64(len(f.Value))
+			case *types.FieldMemberBlobValue:
+				size += int64(len(f.Value))
+			default:
+				size += 8
+			}
+		}
+	}
+	return size
+}
+
+func (r *Rows) columnMetadata() []types.ColumnMetadata {
+	columns := make([]types.ColumnMetadata, 0, len(r.names))
+	for i, name := range r.names {
+		typeName := r.typeNames[i]
+		if typeName == "" {
+			typeName = "varchar"
+		}
+		var nullable int32 = 1
+		if r.notNull[i] {
+			nullable = 0
+		}
+		columns = append(columns, types.ColumnMetadata{
+			Name:     aws.String(name),
+			Label:    aws.String(name),
+			TypeName: aws.String(typeName),
+			Nullable: nullable,
+		})
+	}
+	return columns
+}
+
+func (r *Rows) page(index int) ([][]types.Field, bool) {
+	if r.pageSize <= 0 {
+		return r.records, false
+	}
+	start := index * r.pageSize
+	if start > len(r.records) {
+		start = len(r.records)
+	}
+	end := start + r.pageSize
+	if end >= len(r.records) {
+		return r.records[start:], false
+	}
+	return r.records[start:end], true
+}
+
+// csv writes records as the CSV of GetStatementResultV2, after a header line if header is set.
+func (r *Rows) csv(records [][]types.Field, header bool) (string, error) {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	if header {
+		if err := w.Write(r.names); err != nil {
+			return "", err
+		}
+	}
+	for _, record := range records {
+		values := make([]string, len(record))
+		for i, field := range record {
+			values[i] = csvValue(field)
+		}
+		if err := w.Write(values); err != nil {
+			return "", err
+		}
+	}
+	w.Flush()
+	return b.String(), w.Error()
+}
+
+func csvValue(field types.Field) string {
+	switch f := field.(type) {
+	case *types.FieldMemberStringValue:
+		return f.Value
+	case *types.FieldMemberBooleanValue:
+		return strconv.FormatBool(f.Value)
+	case *types.FieldMemberLongValue:
+		return strconv.FormatInt(f.Value, 10)
+	case *types.FieldMemberDoubleValue:
+		return strconv.FormatFloat(f.Value, 'g', -1, 64)
+	case *types.FieldMemberBlobValue:
+		return hex.EncodeToString(f.Value)
+	default:
+		return ""
+	}
+}
+
+func toField(value any) (types.Field, string) {
+	switch v := value.(type) {
+	case nil:
+		return &types.FieldMemberIsNull{Value: true}, ""
+	case string:
+		return &types.FieldMemberStringValue{Value: v}, "varchar"
+	case bool:
+		return &types.FieldMemberBooleanValue{Value: v}, "bool"
+	case int:
+		return &types.FieldMemberLongValue{Value: int64(v)}, "int8"
+	case int8:
+		return &types.FieldMemberLongValue{Value: int64(v)}, "int2"
+	case int16:
+		return &types.FieldMemberLongValue{Value: int64(v)}, "int2"
+	case int32:
+		return &types.FieldMemberLongValue{Value: int64(v)}, "int4"
+	case int64:
+		return &types.FieldMemberLongValue{Value: v}, "int8"
+	case uint8:
+		return &types.FieldMemberLongValue{Value: int64(v)}, "int2"
+	case uint16:
+		return &types.FieldMemberLongValue{Value: int64(v)}, "int4"
+	case uint32:
+		return &types.FieldMemberLongValue{Value: int64(v)}, "int8"
+	case float32:
+		return &types.FieldMemberDoubleValue{Value: float64(v)}, "float4"
+	case float64:
+		return &types.FieldMemberDoubleValue{Value: v}, "float8"
+	case []byte:
+		return &types.FieldMemberBlobValue{Value: v}, "varbyte"
+	case time.Time:
+		return &types.FieldMemberStringValue{Value: v.UTC().Format("2006-01-02 15:04:05")}, "timestamp"
+	default:
+		return &types.FieldMemberStringValue{Value: fmt.Sprint(v)}, "varchar"
+	}
+}
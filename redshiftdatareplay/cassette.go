@@ -0,0 +1,303 @@
+// Package redshiftdatareplay records Redshift Data API traffic to golden files and
+// serves it back offline.
+//
+// A Recorder wraps a real client and captures every ExecuteStatement and
+// BatchExecuteStatement exchange together with its final DescribeStatement output and
+// result pages. A Replayer loads the file and answers the same calls, matched by SQL and
+// parameters, without contacting AWS.
+package redshiftdatareplay
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
+)
+
+type cassette struct {
+	Interactions []*interaction `json:"interactions"`
+}
+
+type interaction struct {
+	Operation  string         `json:"operation"`
+	SQL        string         `json:"sql,omitempty"`
+	Sqls       []string       `json:"sqls,omitempty"`
+	Parameters []parameter    `json:"parameters,omitempty"`
+	Error      string         `json:"error,omitempty"`
+	Describe   *describe      `json:"describe,omitempty"`
+	Pages      []*resultPage  `json:"pages,omitempty"`
+	tokens     map[string]int `json:"-"`
+}
+
+type parameter struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type describe struct {
+	Status          types.StatusString `json:"status"`
+	Error           string             `json:"error,omitempty"`
+	HasResultSet    bool               `json:"has_result_set"`
+	ResultRows      int64              `json:"result_rows"`
+	ResultSize      int64              `json:"result_size"`
+	RedshiftQueryId int64              `json:"redshift_query_id"`
+	RedshiftPid     int64              `json:"redshift_pid"`
+	Duration        int64              `json:"duration"`
+	SessionId       string             `json:"session_id,omitempty"`
+	SubStatements   []*subStatement    `json:"sub_statements,omitempty"`
+}
+
+type subStatement struct {
+	Status          types.StatementStatusString `json:"status"`
+	Error           string                      `json:"error,omitempty"`
+	QueryString     string                      `json:"query_string"`
+	HasResultSet    bool                        `json:"has_result_set"`
+	ResultRows      int64                       `json:"result_rows"`
+	ResultSize      int64                       `json:"result_size"`
+	RedshiftQueryId int64                       `json:"redshift_query_id"`
+	Duration        int64                       `json:"duration"`
+}
+
+type resultPage struct {
+	Columns      []column  `json:"columns"`
+	Records      [][]field `json:"records"`
+	TotalNumRows int64     `json:"total_num_rows"`
+}
+
+type column struct {
+	Name            string  `json:"name"`
+	Label           string  `json:"label,omitempty"`
+	TypeName        string  `json:"type_name"`
+	SchemaName      string  `json:"schema_name,omitempty"`
+	TableName       string  `json:"table_name,omitempty"`
+	ColumnDefault   *string `json:"column_default,omitempty"`
+	IsCaseSensitive bool    `json:"is_case_sensitive,omitempty"`
+	IsCurrency      bool    `json:"is_currency,omitempty"`
+	IsSigned        bool    `json:"is_signed,omitempty"`
+	Length          int32   `json:"length,omitempty"`
+	Nullable        int32   `json:"nullable,omitempty"`
+	Precision       int32   `json:"precision,omitempty"`
+	Scale           int32   `json:"scale,omitempty"`
+}
+
+type field struct {
+	IsNull       bool     `json:"is_null,omitempty"`
+	StringValue  *string  `json:"string_value,omitempty"`
+	LongValue    *int64   `json:"long_value,omitempty"`
+	DoubleValue  *float64 `json:"double_value,omitempty"`
+	BooleanValue *bool    `json:"boolean_value,omitempty"`
+	BlobValue    []byte   `json:"blob_value,omitempty"`
+}
+
+func loadCassette(path string) (*cassette, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c cassette
+	if err := json.Unmarshal(bs, &c); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+func (c *cassette) save(path string) error {
+	bs, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(bs, '\n'), 0644)
+}
+
+// queryComment matches the sqlcommenter comment the driver appends to the SQL it sends,
+// such as /*app='api',traceparent='00-...'*/, before an optional trailing semicolon.
+var queryComment = regexp.MustCompile(`\s*/\*[^'*/=,]+='[^'*/]*'(,[^'*/=,]+='[^'*/]*')*\*/(;?)\s*$`)
+
+// stripQueryComment removes the sqlcommenter comment, whose tags such as traceparent
+// may differ on every run, so that statements are recorded and matched without it.
+func stripQueryComment(sql string) string {
+	return queryComment.ReplaceAllString(sql, "$2")
+}
+
+func fromParameters(params []types.SqlParameter) []parameter {
+	if len(params) == 0 {
+		return nil
+	}
+	ps := make([]parameter, 0, len(params))
+	for _, p := range params {
+		ps = append(ps, parameter{
+			Name:  aws.ToString(p.Name),
+			Value: aws.ToString(p.Value),
+		})
+	}
+	return ps
+}
+
+func fromDescribe(output *redshiftdata.DescribeStatementOutput) *describe {
+	d := &describe{
+		Status:          output.Status,
+		Error:           aws.ToString(output.Error),
+		HasResultSet:    aws.ToBool(output.HasResultSet),
+		ResultRows:      output.ResultRows,
+		ResultSize:      output.ResultSize,
+		RedshiftQueryId: output.RedshiftQueryId,
+		RedshiftPid:     output.RedshiftPid,
+		Duration:        output.Duration,
+		SessionId:       aws.ToString(output.SessionId),
+	}
+	for _, st := range output.SubStatements {
+		d.SubStatements = append(d.SubStatements, &subStatement{
+			Status:          st.Status,
+			Error:           aws.ToString(st.Error),
+			QueryString:     aws.ToString(st.QueryString),
+			HasResultSet:    aws.ToBool(st.HasResultSet),
+			ResultRows:      st.ResultRows,
+			ResultSize:      st.ResultSize,
+			RedshiftQueryId: st.RedshiftQueryId,
+			Duration:        st.Duration,
+		})
+	}
+	return d
+}
+
+func (d *describe) toOutput(id string, i *interaction, now time.Time) *redshiftdata.DescribeStatementOutput {
+	output := &redshiftdata.DescribeStatementOutput{
+		Id:              aws.String(id),
+		Status:          d.Status,
+		HasResultSet:    aws.Bool(d.HasResultSet),
+		ResultRows:      d.ResultRows,
+		ResultSize:      d.ResultSize,
+		RedshiftQueryId: d.RedshiftQueryId,
+		RedshiftPid:     d.RedshiftPid,
+		Duration:        d.Duration,
+		CreatedAt:       aws.Time(now),
+		UpdatedAt:       aws.Time(now),
+	}
+	if d.Error != "" {
+		output.Error = aws.String(d.Error)
+	}
+	if d.SessionId != "" {
+		output.SessionId = aws.String(d.SessionId)
+	}
+	if i.Operation == operationExecuteStatement {
+		output.QueryString = aws.String(i.SQL)
+	}
+	for n, st := range d.SubStatements {
+		sub := types.SubStatementData{
+			Id:              aws.String(fmt.Sprintf("%s:%d", id, n+1)),
+			Status:          st.Status,
+			QueryString:     aws.String(st.QueryString),
+			HasResultSet:    aws.Bool(st.HasResultSet),
+			ResultRows:      st.ResultRows,
+			ResultSize:      st.ResultSize,
+			RedshiftQueryId: st.RedshiftQueryId,
+			Duration:        st.Duration,
+			CreatedAt:       aws.Time(now),
+			UpdatedAt:       aws.Time(now),
+		}
+		if st.Error != "" {
+			sub.Error = aws.String(st.Error)
+		}
+		output.SubStatements = append(output.SubStatements, sub)
+	}
+	return output
+}
+
+func fromResultPage(output *redshiftdata.GetStatementResultOutput) *resultPage {
+	page := &resultPage{
+		Columns:      make([]column, 0, len(output.ColumnMetadata)),
+		Records:      make([][]field, 0, len(output.Records)),
+		TotalNumRows: output.TotalNumRows,
+	}
+	for _, meta := range output.ColumnMetadata {
+		page.Columns = append(page.Columns, column{
+			Name:            aws.ToString(meta.Name),
+			Label:           aws.ToString(meta.Label),
+			TypeName:        aws.ToString(meta.TypeName),
+			SchemaName:      aws.ToString(meta.SchemaName),
+			TableName:       aws.ToString(meta.TableName),
+			ColumnDefault:   meta.ColumnDefault,
+			IsCaseSensitive: meta.IsCaseSensitive,
+			IsCurrency:      meta.IsCurrency,
+			IsSigned:        meta.IsSigned,
+			Length:          meta.Length,
+			Nullable:        meta.Nullable,
+			Precision:       meta.Precision,
+			Scale:           meta.Scale,
+		})
+	}
+	for _, record := range output.Records {
+		fields := make([]field, 0, len(record))
+		for _, f := range record {
+			switch v := f.(type) {
+			case *types.FieldMemberIsNull:
+				fields = append(fields, field{IsNull: true})
+			case *types.FieldMemberStringValue:
+				fields = append(fields, field{StringValue: aws.String(v.Value)})
+			case *types.FieldMemberLongValue:
+				fields = append(fields, field{LongValue: aws.Int64(v.Value)})
+			case *types.FieldMemberDoubleValue:
+				fields = append(fields, field{DoubleValue: aws.Float64(v.Value)})
+			case *types.FieldMemberBooleanValue:
+				fields = append(fields, field{BooleanValue: aws.Bool(v.Value)})
+			case *types.FieldMemberBlobValue:
+				fields = append(fields, field{BlobValue: v.Value})
+			default:
+				fields = append(fields, field{IsNull: true})
+			}
+		}
+		page.Records = append(page.Records, fields)
+	}
+	return page
+}
+
+func (p *resultPage) toOutput() *redshiftdata.GetStatementResultOutput {
+	output := &redshiftdata.GetStatementResultOutput{
+		ColumnMetadata: make([]types.ColumnMetadata, 0, len(p.Columns)),
+		Records:        make([][]types.Field, 0, len(p.Records)),
+		TotalNumRows:   p.TotalNumRows,
+	}
+	for _, c := range p.Columns {
+		output.ColumnMetadata = append(output.ColumnMetadata, types.ColumnMetadata{
+			Name:            aws.String(c.Name),
+			Label:           aws.String(c.Label),
+			TypeName:        aws.String(c.TypeName),
+			SchemaName:      aws.String(c.SchemaName),
+			TableName:       aws.String(c.TableName),
+			ColumnDefault:   c.ColumnDefault,
+			IsCaseSensitive: c.IsCaseSensitive,
+			IsCurrency:      c.IsCurrency,
+			IsSigned:        c.IsSigned,
+			Length:          c.Length,
+			Nullable:        c.Nullable,
+			Precision:       c.Precision,
+			Scale:           c.Scale,
+		})
+	}
+	for _, record := range p.Records {
+		fields := make([]types.Field, 0, len(record))
+		for _, f := range record {
+			switch {
+			case f.StringValue != nil:
+				fields = append(fields, &types.FieldMemberStringValue{Value: *f.StringValue})
+			case f.LongValue != nil:
+				fields = append(fields, &types.FieldMemberLongValue{Value: *f.LongValue})
+			case f.DoubleValue != nil:
+				fields = append(fields, &types.FieldMemberDoubleValue{Value: *f.DoubleValue})
+			case f.BooleanValue != nil:
+				fields = append(fields, &types.FieldMemberBooleanValue{Value: *f.BooleanValue})
+			case f.BlobValue != nil:
+				fields = append(fields, &types.FieldMemberBlobValue{Value: f.BlobValue})
+			default:
+				fields = append(fields, &types.FieldMemberIsNull{Value: true})
+			}
+		}
+		output.Records = append(output.Records, fields)
+	}
+	return output
+}
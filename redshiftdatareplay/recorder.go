@@ -0,0 +1,142 @@
+package redshiftdatareplay
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
+)
+
+const (
+	operationExecuteStatement      = "ExecuteStatement"
+	operationBatchExecuteStatement = "BatchExecuteStatement"
+)
+
+// Client is the subset of the Redshift Data API used by the driver.
+// It has the same method set as redshiftdatasqldriver.RedshiftDataClient.
+type Client interface {
+	ExecuteStatement(ctx context.Context, params *redshiftdata.ExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ExecuteStatementOutput, error)
+	DescribeStatement(ctx context.Context, params *redshiftdata.DescribeStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.DescribeStatementOutput, error)
+	CancelStatement(ctx context.Context, params *redshiftdata.CancelStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.CancelStatementOutput, error)
+	BatchExecuteStatement(ctx context.Context, params *redshiftdata.BatchExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.BatchExecuteStatementOutput, error)
+	redshiftdata.GetStatementResultAPIClient
+}
+
+// Recorder passes calls through to a real client and captures the exchanges.
+// Call Save to write them to the golden file.
+type Recorder struct {
+	client Client
+	path   string
+
+	mu       sync.Mutex
+	cassette *cassette
+	byID     map[string]*interaction
+}
+
+// NewRecorder returns a Recorder that writes to path when Save is called.
+func NewRecorder(client Client, path string) *Recorder {
+	return &Recorder{
+		client:   client,
+		path:     path,
+		cassette: &cassette{},
+		byID:     make(map[string]*interaction),
+	}
+}
+
+// Save writes all exchanges recorded so far to the golden file.
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cassette.save(r.path)
+}
+
+func (r *Recorder) record(id *string, i *interaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, i)
+	if id != nil {
+		r.byID[*id] = i
+	}
+}
+
+func (r *Recorder) ExecuteStatement(ctx context.Context, params *redshiftdata.ExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ExecuteStatementOutput, error) {
+	output, err := r.client.ExecuteStatement(ctx, params, optFns...)
+	i := &interaction{
+		Operation:  operationExecuteStatement,
+		SQL:        stripQueryComment(aws.ToString(params.Sql)),
+		Parameters: fromParameters(params.Parameters),
+	}
+	if err != nil {
+		i.Error = err.Error()
+		r.record(nil, i)
+		return nil, err
+	}
+	r.record(output.Id, i)
+	return output, nil
+}
+
+func (r *Recorder) BatchExecuteStatement(ctx context.Context, params *redshiftdata.BatchExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.BatchExecuteStatementOutput, error) {
+	output, err := r.client.BatchExecuteStatement(ctx, params, optFns...)
+	i := &interaction{
+		Operation: operationBatchExecuteStatement,
+		Sqls:      make([]string, 0, len(params.Sqls)),
+	}
+	for _, sql := range params.Sqls {
+		i.Sqls = append(i.Sqls, stripQueryComment(sql))
+	}
+	if err != nil {
+		i.Error = err.Error()
+		r.record(nil, i)
+		return nil, err
+	}
+	r.record(output.Id, i)
+	return output, nil
+}
+
+func (r *Recorder) DescribeStatement(ctx context.Context, params *redshiftdata.DescribeStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.DescribeStatementOutput, error) {
+	output, err := r.client.DescribeStatement(ctx, params, optFns...)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if i, ok := r.byID[aws.ToString(params.Id)]; ok {
+		i.Describe = fromDescribe(output)
+	}
+	return output, nil
+}
+
+func (r *Recorder) CancelStatement(ctx context.Context, params *redshiftdata.CancelStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.CancelStatementOutput, error) {
+	return r.client.CancelStatement(ctx, params, optFns...)
+}
+
+func (r *Recorder) GetStatementResult(ctx context.Context, params *redshiftdata.GetStatementResultInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.GetStatementResultOutput, error) {
+	output, err := r.client.GetStatementResult(ctx, params, optFns...)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i, ok := r.byID[aws.ToString(params.Id)]
+	if !ok {
+		return output, nil
+	}
+	index := 0
+	if params.NextToken != nil {
+		if index, ok = i.tokens[*params.NextToken]; !ok {
+			return output, nil
+		}
+	}
+	if index == 0 {
+		i.Pages = nil
+		i.tokens = make(map[string]int)
+	}
+	if index == len(i.Pages) {
+		i.Pages = append(i.Pages, fromResultPage(output))
+	}
+	if output.NextToken != nil {
+		i.tokens[*output.NextToken] = index + 1
+	}
+	return output, nil
+}
@@ -0,0 +1,152 @@
+package redshiftdatareplay_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
+	redshiftdatasqldriver "github.com/mashiike/redshift-data-sql-driver"
+	"github.com/mashiike/redshift-data-sql-driver/redshiftdatamock"
+	"github.com/mashiike/redshift-data-sql-driver/redshiftdatareplay"
+	"github.com/stretchr/testify/require"
+)
+
+func openDB(client redshiftdatasqldriver.RedshiftDataClient) *sql.DB {
+	cfg := (&redshiftdatasqldriver.RedshiftDataConfig{
+		WorkgroupName: aws.String("default"),
+		Database:      aws.String("dev"),
+		Polling:       time.Millisecond,
+	}).WithRedshiftDataClientConstructor(func(_ context.Context, _ *redshiftdatasqldriver.RedshiftDataConfig) (redshiftdatasqldriver.RedshiftDataClient, error) {
+		return client, nil
+	})
+	return sql.OpenDB(redshiftdatasqldriver.NewConnector(cfg))
+}
+
+type user struct {
+	ID   int64
+	Name sql.NullString
+}
+
+func exercise(t *testing.T, db *sql.DB) ([]user, int64) {
+	t.Helper()
+	rows, err := db.QueryContext(context.Background(), `SELECT id, name FROM users WHERE age > :age`, sql.Named("age", 20))
+	require.NoError(t, err)
+	defer rows.Close()
+	var users []user
+	for rows.Next() {
+		var u user
+		require.NoError(t, rows.Scan(&u.ID, &u.Name))
+		users = append(users, u)
+	}
+	require.NoError(t, rows.Err())
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	_, err = tx.Exec(`DELETE FROM users WHERE id = 1`)
+	require.NoError(t, err)
+	result, err := tx.Exec(`DELETE FROM users WHERE id = 2`)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+	rowsAffected, err := result.RowsAffected()
+	require.NoError(t, err)
+
+	_, err = db.Exec(`DROP TABLE missing`)
+	require.ErrorContains(t, err, `table "missing" does not exist`)
+	return users, rowsAffected
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "golden.json")
+
+	mock := redshiftdatamock.New()
+	mock.ExpectExecute(`SELECT id, name FROM users WHERE age > :age`).
+		WithArgs(sql.Named("age", 20)).
+		WillReturnRows(redshiftdatamock.NewRows("id", "name").AddRow(1, "hoge").AddRow(2, nil).AddRow(3, "piyo").WithPageSize(2))
+	mock.ExpectBatchExecute(`DELETE FROM users WHERE id = 1`, `DELETE FROM users WHERE id = 2`).
+		WillReturnResult(1, 1)
+	mock.ExpectExecute(`DROP TABLE missing`).
+		WillFail(`table "missing" does not exist`)
+	recorder := redshiftdatareplay.NewRecorder(mock, path)
+	recordedUsers, recordedRowsAffected := exercise(t, openDB(recorder))
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, recorder.Save())
+
+	replayer, err := redshiftdatareplay.NewReplayer(path)
+	require.NoError(t, err)
+	replayedUsers, replayedRowsAffected := exercise(t, openDB(replayer))
+	require.Equal(t, recordedUsers, replayedUsers)
+	require.Equal(t, recordedRowsAffected, replayedRowsAffected)
+	require.Equal(t, []user{
+		{ID: 1, Name: sql.NullString{String: "hoge", Valid: true}},
+		{ID: 2},
+		{ID: 3, Name: sql.NullString{String: "piyo", Valid: true}},
+	}, replayedUsers)
+	require.Equal(t, int64(1), replayedRowsAffected)
+}
+
+func TestReplayUnknownQuery(t *testing.T) {
+	replayer, err := redshiftdatareplay.NewReplayer(filepath.Join("testdata", "golden.json"))
+	require.NoError(t, err)
+	db := openDB(replayer)
+
+	var n int64
+	require.NoError(t, db.QueryRow(`SELECT count(*) FROM users`).Scan(&n))
+	require.Equal(t, int64(3), n)
+	_, err = db.Exec(`SELECT count(*) FROM orders`)
+	require.ErrorContains(t, err, "no recorded ExecuteStatement")
+}
+
+func TestReplayIgnoresQueryComment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "golden.json")
+	query := func(db *sql.DB, traceparent string) int64 {
+		ctx := redshiftdatasqldriver.WithQueryTags(context.Background(), map[string]string{"traceparent": traceparent})
+		var n int64
+		require.NoError(t, db.QueryRowContext(ctx, `SELECT count(*) FROM users`).Scan(&n))
+		return n
+	}
+
+	mock := redshiftdatamock.New()
+	mock.ExpectExecuteMatch(`^SELECT count\(\*\) FROM users /\*traceparent='00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01'\*/$`).
+		WillReturnRows(redshiftdatamock.NewRows("count").AddRow(3))
+	recorder := redshiftdatareplay.NewRecorder(mock, path)
+	require.Equal(t, int64(3), query(openDB(recorder), "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"))
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, recorder.Save())
+
+	replayer, err := redshiftdatareplay.NewReplayer(path)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), query(openDB(replayer), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"))
+}
+
+func TestReplaySessionId(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "golden.json")
+	begin := &redshiftdata.ExecuteStatementInput{
+		Sql:                     aws.String("BEGIN"),
+		WorkgroupName:           aws.String("default"),
+		SessionKeepAliveSeconds: aws.Int32(60),
+	}
+
+	mock := redshiftdatamock.New()
+	mock.ExpectExecute(`BEGIN`)
+	recorder := redshiftdatareplay.NewRecorder(mock, path)
+	output, err := recorder.ExecuteStatement(ctx, begin)
+	require.NoError(t, err)
+	recorded, err := recorder.DescribeStatement(ctx, &redshiftdata.DescribeStatementInput{Id: output.Id})
+	require.NoError(t, err)
+	require.NotNil(t, recorded.SessionId)
+	require.NoError(t, recorder.Save())
+
+	replayer, err := redshiftdatareplay.NewReplayer(path)
+	require.NoError(t, err)
+	output, err = replayer.ExecuteStatement(ctx, begin)
+	require.NoError(t, err)
+	require.Equal(t, recorded.SessionId, output.SessionId)
+	replayed, err := replayer.DescribeStatement(ctx, &redshiftdata.DescribeStatementInput{Id: output.Id})
+	require.NoError(t, err)
+	require.Equal(t, recorded.SessionId, replayed.SessionId)
+}
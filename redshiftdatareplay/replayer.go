@@ -0,0 +1,205 @@
+package redshiftdatareplay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
+)
+
+// Replayer serves exchanges captured by a Recorder without contacting AWS.
+//
+// Calls are matched by SQL, without the sqlcommenter comment the driver appends, and
+// parameters. Identical calls are served in the order they
+// were recorded, and the last match is repeated once they are used up.
+type Replayer struct {
+	mu         sync.Mutex
+	cassette   *cassette
+	used       map[*interaction]bool
+	statements map[string]*interaction
+	seq        int
+}
+
+// NewReplayer loads the golden file written by Recorder.Save.
+func NewReplayer(path string) (*Replayer, error) {
+	c, err := loadCassette(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Replayer{
+		cassette:   c,
+		used:       make(map[*interaction]bool),
+		statements: make(map[string]*interaction),
+	}, nil
+}
+
+func (r *Replayer) find(match func(*interaction) bool) *interaction {
+	var last *interaction
+	for _, i := range r.cassette.Interactions {
+		if !match(i) {
+			continue
+		}
+		if !r.used[i] {
+			r.used[i] = true
+			return i
+		}
+		last = i
+	}
+	return last
+}
+
+func (r *Replayer) start(i *interaction) (string, error) {
+	if i.Error != "" {
+		return "", errors.New(i.Error)
+	}
+	r.seq++
+	id := fmt.Sprintf("replay-statement-%08d", r.seq)
+	r.statements[id] = i
+	return id, nil
+}
+
+func (r *Replayer) ExecuteStatement(ctx context.Context, params *redshiftdata.ExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ExecuteStatementOutput, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sql := stripQueryComment(aws.ToString(params.Sql))
+	parameters := fromParameters(params.Parameters)
+	i := r.find(func(i *interaction) bool {
+		return i.Operation == operationExecuteStatement && stripQueryComment(i.SQL) == sql && equalParameters(i.Parameters, parameters)
+	})
+	if i == nil {
+		return nil, fmt.Errorf("redshiftdatareplay: no recorded ExecuteStatement for sql=%q parameters=%v", sql, parameters)
+	}
+	id, err := r.start(i)
+	if err != nil {
+		return nil, err
+	}
+	sessionID := params.SessionId
+	if sessionID == nil && i.Describe != nil && i.Describe.SessionId != "" {
+		sessionID = aws.String(i.Describe.SessionId)
+	}
+	return &redshiftdata.ExecuteStatementOutput{
+		Id:                aws.String(id),
+		CreatedAt:         aws.Time(time.Now()),
+		ClusterIdentifier: params.ClusterIdentifier,
+		Database:          params.Database,
+		DbUser:            params.DbUser,
+		SecretArn:         params.SecretArn,
+		WorkgroupName:     params.WorkgroupName,
+		SessionId:         sessionID,
+	}, nil
+}
+
+func (r *Replayer) BatchExecuteStatement(ctx context.Context, params *redshiftdata.BatchExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.BatchExecuteStatementOutput, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.find(func(i *interaction) bool {
+		return i.Operation == operationBatchExecuteStatement && equalSQLs(i.Sqls, params.Sqls)
+	})
+	if i == nil {
+		return nil, fmt.Errorf("redshiftdatareplay: no recorded BatchExecuteStatement for sqls=%q", params.Sqls)
+	}
+	id, err := r.start(i)
+	if err != nil {
+		return nil, err
+	}
+	return &redshiftdata.BatchExecuteStatementOutput{
+		Id:                aws.String(id),
+		CreatedAt:         aws.Time(time.Now()),
+		ClusterIdentifier: params.ClusterIdentifier,
+		Database:          params.Database,
+		DbUser:            params.DbUser,
+		SecretArn:         params.SecretArn,
+		WorkgroupName:     params.WorkgroupName,
+	}, nil
+}
+
+func (r *Replayer) lookup(id *string) (*interaction, error) {
+	i, ok := r.statements[aws.ToString(id)]
+	if !ok {
+		return nil, &types.ResourceNotFoundException{
+			Message:    aws.String(fmt.Sprintf("Query does not exist: %s", aws.ToString(id))),
+			ResourceId: id,
+		}
+	}
+	return i, nil
+}
+
+func (r *Replayer) DescribeStatement(ctx context.Context, params *redshiftdata.DescribeStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.DescribeStatementOutput, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i, err := r.lookup(params.Id)
+	if err != nil {
+		return nil, err
+	}
+	if i.Describe == nil {
+		return nil, fmt.Errorf("redshiftdatareplay: no recorded DescribeStatement for %s", aws.ToString(params.Id))
+	}
+	return i.Describe.toOutput(aws.ToString(params.Id), i, time.Now()), nil
+}
+
+func (r *Replayer) CancelStatement(ctx context.Context, params *redshiftdata.CancelStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.CancelStatementOutput, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.lookup(params.Id); err != nil {
+		return nil, err
+	}
+	return &redshiftdata.CancelStatementOutput{
+		Status: aws.Bool(true),
+	}, nil
+}
+
+func (r *Replayer) GetStatementResult(ctx context.Context, params *redshiftdata.GetStatementResultInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.GetStatementResultOutput, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i, err := r.lookup(params.Id)
+	if err != nil {
+		return nil, err
+	}
+	index := 0
+	if params.NextToken != nil {
+		index, err = strconv.Atoi(*params.NextToken)
+		if err != nil {
+			return nil, &types.ValidationException{
+				Message: aws.String(fmt.Sprintf("invalid next token: %s", *params.NextToken)),
+			}
+		}
+	}
+	if index >= len(i.Pages) {
+		return nil, fmt.Errorf("redshiftdatareplay: no recorded GetStatementResult page %d for %s", index, aws.ToString(params.Id))
+	}
+	output := i.Pages[index].toOutput()
+	if index+1 < len(i.Pages) {
+		output.NextToken = aws.String(strconv.Itoa(index + 1))
+	}
+	return output, nil
+}
+
+func equalParameters(a, b []parameter) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func equalSQLs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if stripQueryComment(a[i]) != stripQueryComment(b[i]) {
+			return false
+		}
+	}
+	return true
+}
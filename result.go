@@ -1,8 +1,10 @@
 package redshiftdatasqldriver
 
 import (
+	"context"
 	"database/sql/driver"
 	"fmt"
+	"sync"
 
 	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
 	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
@@ -12,15 +14,15 @@ type redshiftDataResult struct {
 	affectedRows int64
 }
 
-func newResult(output *redshiftdata.DescribeStatementOutput) *redshiftDataResult {
-	debugLogger.Printf("[%s] create result", coalesce(output.Id))
+func newResult(cfg *RedshiftDataConfig, output *redshiftdata.DescribeStatementOutput) *redshiftDataResult {
+	cfg.logDebug(context.Background(), "create result", statementIDAttr(output.Id))
 	return &redshiftDataResult{
 		affectedRows: output.ResultRows,
 	}
 }
 
-func newResultWithSubStatementData(st types.SubStatementData) *redshiftDataResult {
-	debugLogger.Printf("[%s] create result", coalesce(st.Id))
+func newResultWithSubStatementData(cfg *RedshiftDataConfig, st types.SubStatementData) *redshiftDataResult {
+	cfg.logDebug(context.Background(), "create result", statementIDAttr(st.Id))
 	return &redshiftDataResult{
 		affectedRows: st.ResultRows,
 	}
@@ -35,21 +37,35 @@ func (r *redshiftDataResult) RowsAffected() (int64, error) {
 }
 
 type redshiftDataDelayedResult struct {
-	driver.Result
+	cfg    *RedshiftDataConfig
+	mu     sync.Mutex
+	result driver.Result
+}
+
+func (r *redshiftDataDelayedResult) set(result driver.Result) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.result = result
+}
+
+func (r *redshiftDataDelayedResult) get() driver.Result {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.result
 }
 
 func (r *redshiftDataDelayedResult) LastInsertId() (int64, error) {
-	debugLogger.Printf("delayed result LastInsertId called")
-	if r.Result != nil {
-		return r.Result.LastInsertId()
+	r.cfg.logDebug(context.Background(), "delayed result LastInsertId called")
+	if result := r.get(); result != nil {
+		return result.LastInsertId()
 	}
 	return 0, ErrBeforeCommit
 }
 
 func (r *redshiftDataDelayedResult) RowsAffected() (int64, error) {
-	debugLogger.Printf("delayed result RowsAffected called")
-	if r.Result != nil {
-		return r.Result.RowsAffected()
+	r.cfg.logDebug(context.Background(), "delayed result RowsAffected called")
+	if result := r.get(); result != nil {
+		return result.RowsAffected()
 	}
 	return 0, ErrBeforeCommit
 }
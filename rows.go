@@ -4,37 +4,52 @@ import (
 	"context"
 	"database/sql/driver"
 	"io"
+	"log/slog"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
 	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type redshiftDataRows struct {
+	cfg         *RedshiftDataConfig
 	id          string
-	p           *redshiftdata.GetStatementResultPaginator
+	p           resultPaginator
 	resultSet   *redshiftdata.GetStatementResultOutput
 	columns     []types.ColumnMetadata
 	columnNames []string
 	index       int
+	page        int
+	span        trace.Span
+	ctx         context.Context
+	stmt        *HookStatement
 }
 
-func newRows(id string, p *redshiftdata.GetStatementResultPaginator) *redshiftDataRows {
-	debugLogger.Printf("[%s] create rows", id)
+func newRows(cfg *RedshiftDataConfig, id string, p resultPaginator) *redshiftDataRows {
+	cfg.logDebug(context.Background(), "create rows", slog.String("statement_id", id))
 	return &redshiftDataRows{
-		id: id,
-		p:  p,
+		cfg: cfg,
+		id:  id,
+		p:   p,
+		ctx: context.Background(),
 	}
 }
 
 func (rows *redshiftDataRows) Close() (err error) {
-	debugLogger.Printf("[%s] rows close called", rows.id)
+	rows.cfg.logDebug(context.Background(), "rows close called", slog.String("statement_id", rows.id))
+	if rows.span != nil {
+		rows.span.End()
+		rows.span = nil
+	}
 	return nil
 }
 
 func (rows *redshiftDataRows) Columns() []string {
-	debugLogger.Printf("[%s] rows columns called", rows.id)
+	rows.cfg.logDebug(context.Background(), "rows columns called", slog.String("statement_id", rows.id))
 	if rows.columnNames != nil {
 		return rows.columnNames
 	}
@@ -49,10 +64,29 @@ func (rows *redshiftDataRows) getStatementResult() error {
 		return io.EOF
 	}
 	var err error
+	start := time.Now()
 	rows.resultSet, err = rows.p.NextPage(context.Background())
 	if err != nil {
+		if rows.span != nil {
+			rows.span.RecordError(err)
+			rows.span.SetStatus(codes.Error, err.Error())
+		}
 		return err
 	}
+	rows.page++
+	if rows.span != nil {
+		rows.span.AddEvent("page fetch", trace.WithAttributes(
+			attribute.Int("db.redshift.page", rows.page),
+			attribute.Int("db.redshift.records", len(rows.resultSet.Records)),
+		))
+	}
+	rows.cfg.logDebug(context.Background(), "fetch result page",
+		slog.String("statement_id", rows.id),
+		slog.Int("page", rows.page),
+		slog.Int("records", len(rows.resultSet.Records)),
+		slog.Int64("total_num_rows", rows.resultSet.TotalNumRows),
+		elapsedAttr(start),
+	)
 	rows.columns = rows.resultSet.ColumnMetadata
 	rows.columnNames = make([]string, 0, len(rows.columns))
 	for _, meta := range rows.columns {
@@ -63,9 +97,9 @@ func (rows *redshiftDataRows) getStatementResult() error {
 }
 
 func (rows *redshiftDataRows) Next(dest []driver.Value) error {
-	debugLogger.Printf("[%s] rows next called", rows.id)
+	rows.cfg.logDebug(context.Background(), "rows next called", slog.String("statement_id", rows.id))
 	if rows.resultSet == nil || rows.index >= len(rows.resultSet.Records) {
-		if !rows.p.HasMorePages() {
+		if rows.p == nil || !rows.p.HasMorePages() {
 			return io.EOF
 		}
 		if err := rows.getStatementResult(); err != nil {
@@ -87,7 +121,7 @@ func (rows *redshiftDataRows) Next(dest []driver.Value) error {
 				case strings.EqualFold(*rows.resultSet.ColumnMetadata[i].TypeName, "timestamp"):
 					t, err := time.Parse("2006-01-02 15:04:05", field.Value)
 					if err != nil {
-						errLogger.Printf(`time.Parse("2006-01-02 15:04:05", "%s"): %v`, field.Value, err)
+						rows.cfg.logError(context.Background(), "parse timestamp", slog.String("statement_id", rows.id), slog.String("value", field.Value), slog.Any("error", err))
 						dest[i] = nil
 					} else {
 						dest[i] = t
@@ -95,7 +129,7 @@ func (rows *redshiftDataRows) Next(dest []driver.Value) error {
 				case strings.EqualFold(*rows.resultSet.ColumnMetadata[i].TypeName, "timestamptz"):
 					t, err := time.Parse("2006-01-02 15:04:05-07", field.Value)
 					if err != nil {
-						errLogger.Printf(`time.Parse("2006-01-02 15:04:05-07", "%s"): %v`, field.Value, err)
+						rows.cfg.logError(context.Background(), "parse timestamptz", slog.String("statement_id", rows.id), slog.String("value", field.Value), slog.Any("error", err))
 						dest[i] = nil
 					} else {
 						dest[i] = t
@@ -115,5 +149,5 @@ func (rows *redshiftDataRows) Next(dest []driver.Value) error {
 		}
 	}
 	rows.index++
-	return nil
+	return rows.cfg.onRow(rows.ctx, rows.stmt, rows.columnNames, dest)
 }
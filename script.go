@@ -0,0 +1,116 @@
+package redshiftdatasqldriver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+)
+
+const (
+	// maxBatchStatements is the number of statements BatchExecuteStatement accepts at most.
+	maxBatchStatements = 40
+	// maxBatchSQLBytes is the size of the SQL the Data API accepts in one call.
+	maxBatchSQLBytes = 100 * 1024
+)
+
+// ScriptResult is the result of a script of several statements.
+type ScriptResult struct {
+	// Statements holds the statements that were run, as split from the script.
+	Statements []string
+	// StatementRowsAffected holds the rows affected by each statement.
+	StatementRowsAffected []int64
+}
+
+func (r *ScriptResult) LastInsertId() (int64, error) {
+	return 0, fmt.Errorf("LastInsertId %w", ErrNotSupported)
+}
+
+// RowsAffected returns the total of the rows affected by the statements.
+func (r *ScriptResult) RowsAffected() (int64, error) {
+	var total int64
+	for _, n := range r.StatementRowsAffected {
+		total += n
+	}
+	return total, nil
+}
+
+// ExecScript runs a script of ;-separated statements on db and returns the rows affected by each statement.
+// The statements are sent in batches, each of which runs in its own transaction,
+// so if a batch fails the result holds the statements of the batches before it.
+func ExecScript(ctx context.Context, db *sql.DB, script string) (*ScriptResult, error) {
+	c, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	var result *ScriptResult
+	err = c.Raw(func(driverConn any) error {
+		conn, ok := driverConn.(*redshiftDataConn)
+		if !ok {
+			return fmt.Errorf("exec script on %T: %w", driverConn, ErrNotSupported)
+		}
+		result, err = conn.execScript(ctx, splitStatements(script))
+		return err
+	})
+	return result, err
+}
+
+func (conn *redshiftDataConn) execScript(ctx context.Context, stmts []string) (*ScriptResult, error) {
+	batches := splitBatches(stmts, conn.cfg.taggedSize(ctx))
+	conn.cfg.logDebug(ctx, "exec script", slog.Int("statements", len(stmts)), slog.Int("batches", len(batches)))
+	result := &ScriptResult{}
+	for _, batch := range batches {
+		results, err := conn.execBatch(ctx, HookKindScript, batch)
+		if err != nil {
+			return result, fmt.Errorf("script statements %d-%d: %w", len(result.Statements)+1, len(result.Statements)+len(batch), err)
+		}
+		for i, r := range results {
+			n, err := r.RowsAffected()
+			if err != nil {
+				return result, err
+			}
+			result.Statements = append(result.Statements, batch[i])
+			result.StatementRowsAffected = append(result.StatementRowsAffected, n)
+		}
+	}
+	return result, nil
+}
+
+// oversizedStatement returns the index of the first of sqls over the size the Data API accepts, or -1.
+// size measures a statement as it is sent.
+func oversizedStatement(sqls []string, size func(string) int) int {
+	for i, sql := range sqls {
+		if size(sql) > maxBatchSQLBytes {
+			return i
+		}
+	}
+	return -1
+}
+
+// exceedsBatchLimits reports whether sqls do not fit in one BatchExecuteStatement.
+func exceedsBatchLimits(sqls []string, size func(string) int) bool {
+	return len(splitBatches(sqls, size)) > 1
+}
+
+// splitBatches groups stmts in order into batches within the statement count and size limits of BatchExecuteStatement.
+// A statement over the size limit gets a batch of its own and is left for the Data API to reject.
+// size measures a statement as it is sent.
+func splitBatches(stmts []string, size func(string) int) [][]string {
+	var batches [][]string
+	var batch []string
+	var batchSize int
+	for _, stmt := range stmts {
+		n := size(stmt)
+		if len(batch) > 0 && (len(batch) == maxBatchStatements || batchSize+n > maxBatchSQLBytes) {
+			batches = append(batches, batch)
+			batch, batchSize = nil, 0
+		}
+		batch = append(batch, stmt)
+		batchSize += n
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
@@ -0,0 +1,109 @@
+package redshiftdatasqldriver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mashiike/redshift-data-sql-driver/redshiftdatamock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitStatements(t *testing.T) {
+	cases := []struct {
+		casename string
+		script   string
+		expected []string
+	}{
+		{
+			casename: "single",
+			script:   `SELECT 1;`,
+			expected: []string{`SELECT 1`},
+		},
+		{
+			casename: "quotes and comments",
+			script:   "-- create users; and sessions\nCREATE TABLE users (name varchar(10) DEFAULT 'a;b');\n/* ; */\nINSERT INTO \"a;b\" VALUES (1) ; ; -- done;\n",
+			expected: []string{
+				"-- create users; and sessions\nCREATE TABLE users (name varchar(10) DEFAULT 'a;b')",
+				"/* ; */\nINSERT INTO \"a;b\" VALUES (1)",
+			},
+		},
+		{
+			casename: "procedure",
+			script:   "CREATE PROCEDURE p() AS $$ BEGIN DELETE FROM t; COMMIT; END; $$ LANGUAGE plpgsql;\nCALL p()",
+			expected: []string{
+				"CREATE PROCEDURE p() AS $$ BEGIN DELETE FROM t; COMMIT; END; $$ LANGUAGE plpgsql",
+				"CALL p()",
+			},
+		},
+		{
+			casename: "comments only",
+			script:   "-- nothing to do;\n",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.casename, func(t *testing.T) {
+			require.Equal(t, c.expected, splitStatements(c.script))
+		})
+	}
+}
+
+func TestSplitBatches(t *testing.T) {
+	stmts := make([]string, 0, 85)
+	for i := 0; i < 85; i++ {
+		stmts = append(stmts, fmt.Sprintf("INSERT INTO t VALUES (%d)", i))
+	}
+	size := func(sql string) int { return len(sql) }
+	batches := splitBatches(stmts, size)
+	require.Len(t, batches, 3)
+	require.Len(t, batches[0], 40)
+	require.Len(t, batches[1], 40)
+	require.Len(t, batches[2], 5)
+
+	large := strings.Repeat("x", maxBatchSQLBytes/2)
+	batches = splitBatches([]string{large, large, "SELECT 1", large + large}, size)
+	require.Equal(t, [][]string{{large, large}, {"SELECT 1"}, {large + large}}, batches)
+
+	// The query comment appended to each statement counts toward the limit.
+	cfg := (&RedshiftDataConfig{}).WithQueryTags(map[string]string{"app": "api"})
+	batches = splitBatches([]string{large, large}, cfg.taggedSize(context.Background()))
+	require.Equal(t, [][]string{{large}, {large}}, batches)
+}
+
+func TestExecScript(t *testing.T) {
+	client := redshiftdatamock.New()
+	var script strings.Builder
+	first := make([]string, 0, 40)
+	rowsAffected := make([]int64, 0, 40)
+	for i := 0; i < 41; i++ {
+		stmt := fmt.Sprintf("INSERT INTO t VALUES (%d)", i)
+		fmt.Fprintf(&script, "%s;\n", stmt)
+		if i < 40 {
+			first = append(first, stmt)
+			rowsAffected = append(rowsAffected, 1)
+		}
+	}
+	client.ExpectBatchExecute(first...).WillReturnResult(rowsAffected...)
+	client.ExpectExecute(`INSERT INTO t VALUES (40)`).WillReturnResult(2)
+	client.ExpectBatchExecute(`DELETE FROM t`, `VACUUM t`).WillReturnResult(41, 0)
+	db := openTestDB(t, client)
+	restore := requireNoErrorLog(t)
+	defer restore()
+
+	result, err := ExecScript(context.Background(), db, script.String())
+	require.NoError(t, err)
+	require.Len(t, result.Statements, 41)
+	require.Equal(t, `INSERT INTO t VALUES (40)`, result.Statements[40])
+	require.EqualValues(t, 2, result.StatementRowsAffected[40])
+	total, err := result.RowsAffected()
+	require.NoError(t, err)
+	require.EqualValues(t, 42, total)
+
+	sqlResult, err := db.ExecContext(context.Background(), "DELETE FROM t;\nVACUUM t;")
+	require.NoError(t, err)
+	total, err = sqlResult.RowsAffected()
+	require.NoError(t, err)
+	require.EqualValues(t, 41, total)
+	require.NoError(t, client.ExpectationsWereMet())
+}
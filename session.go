@@ -0,0 +1,87 @@
+package redshiftdatasqldriver
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
+)
+
+// sessionKeepAliveSeconds keeps a session open between the statements run in it.
+const sessionKeepAliveSeconds = 300
+
+// execInSession runs sqls one by one between BEGIN and COMMIT in a Data API session,
+// so that they are atomic without the limits of BatchExecuteStatement.
+// On failure the session is rolled back.
+func (conn *redshiftDataConn) execInSession(ctx context.Context, kind HookKind, sqls []string) ([]driver.Result, error) {
+	stmt := &HookStatement{
+		Kind: kind,
+		Sqls: append(make([]string, 0, len(sqls)), sqls...),
+	}
+	ctx, err := conn.cfg.beforeExecute(ctx, stmt)
+	if err != nil {
+		return nil, err
+	}
+	if len(stmt.Sqls) != len(sqls) {
+		return nil, conn.cfg.afterExecute(ctx, stmt, nil, errHookChangedStatements)
+	}
+	desc, err := conn.executeInSession(ctx, &redshiftdata.ExecuteStatementInput{
+		Sql:                     aws.String("BEGIN"),
+		SessionKeepAliveSeconds: aws.Int32(sessionKeepAliveSeconds),
+	})
+	if err == nil && desc.SessionId == nil {
+		err = errors.New("session id not returned")
+	}
+	if err != nil {
+		return nil, conn.cfg.afterExecute(ctx, stmt, desc, fmt.Errorf("begin session transaction: %w", err))
+	}
+	sessionID := desc.SessionId
+	conn.cfg.logDebug(ctx, "session transaction started", slog.String("session_id", *sessionID), slog.Int("sqls", len(stmt.Sqls)))
+	results := make([]driver.Result, 0, len(stmt.Sqls))
+	for i, sql := range stmt.Sqls {
+		desc, err = conn.executeInSession(ctx, &redshiftdata.ExecuteStatementInput{
+			Sql:       aws.String(sql),
+			SessionId: sessionID,
+		})
+		if err != nil {
+			conn.rollbackSession(ctx, sessionID)
+			return nil, conn.cfg.afterExecute(ctx, stmt, desc, fmt.Errorf("session transaction statement %d: %w", i+1, err))
+		}
+		results = append(results, newResult(conn.cfg, desc))
+	}
+	desc, err = conn.executeInSession(ctx, &redshiftdata.ExecuteStatementInput{
+		Sql:       aws.String("COMMIT"),
+		SessionId: sessionID,
+	})
+	if err != nil {
+		conn.rollbackSession(ctx, sessionID)
+		err = fmt.Errorf("commit session transaction: %w", err)
+	}
+	if err = conn.cfg.afterExecute(ctx, stmt, desc, err); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (conn *redshiftDataConn) executeInSession(ctx context.Context, params *redshiftdata.ExecuteStatementInput) (*redshiftdata.DescribeStatementOutput, error) {
+	ctx, span := conn.cfg.startSpan(ctx, "ExecuteStatement", coalesce(params.Sql))
+	_, desc, err := conn.executeStatement(ctx, params)
+	endSpan(span, desc, err)
+	return desc, err
+}
+
+// rollbackSession ends the transaction of a failed session, even if ctx is already done.
+func (conn *redshiftDataConn) rollbackSession(ctx context.Context, sessionID *string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), closeCancelTimeout)
+	defer cancel()
+	if _, err := conn.executeInSession(ctx, &redshiftdata.ExecuteStatementInput{
+		Sql:       aws.String("ROLLBACK"),
+		SessionId: sessionID,
+	}); err != nil {
+		conn.cfg.logError(ctx, "failed rollback session transaction", slog.String("session_id", *sessionID), slog.Any("error", err))
+	}
+}
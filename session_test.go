@@ -0,0 +1,137 @@
+package redshiftdatasqldriver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
+	"github.com/mashiike/redshift-data-sql-driver/redshiftdatamock"
+	"github.com/stretchr/testify/require"
+)
+
+type sessionRecordingClient struct {
+	*redshiftdatamock.Client
+	mu     sync.Mutex
+	inputs []*redshiftdata.ExecuteStatementInput
+}
+
+func (c *sessionRecordingClient) ExecuteStatement(ctx context.Context, params *redshiftdata.ExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ExecuteStatementOutput, error) {
+	c.mu.Lock()
+	c.inputs = append(c.inputs, params)
+	c.mu.Unlock()
+	return c.Client.ExecuteStatement(ctx, params, optFns...)
+}
+
+func execInTx(t *testing.T, db *sql.DB, n int) ([]sql.Result, error) {
+	t.Helper()
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	results := make([]sql.Result, 0, n)
+	for i := 0; i < n; i++ {
+		result, err := tx.Exec(fmt.Sprintf("INSERT INTO t VALUES (%d)", i))
+		require.NoError(t, err)
+		results = append(results, result)
+	}
+	return results, tx.Commit()
+}
+
+func TestTxTooLarge(t *testing.T) {
+	client := redshiftdatamock.New()
+	db := openTestDB(t, client)
+	restore := requireNoErrorLog(t)
+	defer restore()
+
+	_, err := execInTx(t, db, maxBatchStatements+1)
+	require.ErrorIs(t, err, ErrTxTooLarge)
+	require.Equal(t, 0, client.CallCount(redshiftdatamock.OperationBatchExecuteStatement))
+	require.Equal(t, 0, client.CallCount(redshiftdatamock.OperationExecuteStatement))
+}
+
+func TestTxStatementTooLarge(t *testing.T) {
+	for _, sessionTransactions := range []bool{false, true} {
+		t.Run(fmt.Sprintf("session_transactions=%v", sessionTransactions), func(t *testing.T) {
+			client := redshiftdatamock.New()
+			db := openTestDB(t, client, func(cfg *RedshiftDataConfig) { cfg.WithSessionTransactions(sessionTransactions) })
+			restore := requireNoErrorLog(t)
+			defer restore()
+
+			tx, err := db.Begin()
+			require.NoError(t, err)
+			_, err = tx.Exec("INSERT INTO t VALUES (1)")
+			require.NoError(t, err)
+			_, err = tx.Exec("INSERT INTO t VALUES ('" + strings.Repeat("x", maxBatchSQLBytes) + "')")
+			require.NoError(t, err)
+			err = tx.Commit()
+			require.ErrorIs(t, err, ErrTxTooLarge)
+			require.ErrorContains(t, err, "commit statement 2 of ")
+			require.Equal(t, 0, client.CallCount(redshiftdatamock.OperationBatchExecuteStatement))
+			require.Equal(t, 0, client.CallCount(redshiftdatamock.OperationExecuteStatement))
+		})
+	}
+}
+
+func TestTxStatementTooLargeWithQueryTags(t *testing.T) {
+	client := redshiftdatamock.New()
+	db := openTestDB(t, client)
+	restore := requireNoErrorLog(t)
+	defer restore()
+
+	// The statement fits the limit, but not with the query comment appended to it.
+	ctx := WithQueryTags(context.Background(), map[string]string{"app": "api"})
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	prefix := "INSERT INTO t VALUES ('"
+	_, err = tx.Exec(prefix + strings.Repeat("x", maxBatchSQLBytes-len(prefix)-2) + "')")
+	require.NoError(t, err)
+	err = tx.Commit()
+	require.ErrorIs(t, err, ErrTxTooLarge)
+	require.Equal(t, 0, client.CallCount(redshiftdatamock.OperationExecuteStatement))
+}
+
+func TestSessionTransactions(t *testing.T) {
+	client := &sessionRecordingClient{Client: redshiftdatamock.New()}
+	client.ExpectExecute(`BEGIN`)
+	for i := 0; i < maxBatchStatements+1; i++ {
+		client.ExpectExecute(fmt.Sprintf("INSERT INTO t VALUES (%d)", i)).WillReturnResult(1)
+	}
+	client.ExpectExecute(`COMMIT`)
+	db := openTestDB(t, client, func(cfg *RedshiftDataConfig) { cfg.WithSessionTransactions(true) })
+	restore := requireNoErrorLog(t)
+	defer restore()
+
+	results, err := execInTx(t, db, maxBatchStatements+1)
+	require.NoError(t, err)
+	for _, result := range results {
+		n, err := result.RowsAffected()
+		require.NoError(t, err)
+		require.EqualValues(t, 1, n)
+	}
+	require.NoError(t, client.ExpectationsWereMet())
+	require.Len(t, client.inputs, maxBatchStatements+3)
+	begin := client.inputs[0]
+	require.EqualValues(t, sessionKeepAliveSeconds, aws.ToInt32(begin.SessionKeepAliveSeconds))
+	require.Equal(t, "default", aws.ToString(begin.WorkgroupName))
+	for _, input := range client.inputs[1:] {
+		require.NotNil(t, input.SessionId)
+		require.Nil(t, input.WorkgroupName)
+		require.Nil(t, input.Database)
+	}
+}
+
+func TestSessionTransactionsRollback(t *testing.T) {
+	client := redshiftdatamock.New()
+	client.ExpectExecute(`BEGIN`)
+	client.ExpectExecute(`INSERT INTO t VALUES (0)`).WillReturnResult(1)
+	client.ExpectExecute(`INSERT INTO t VALUES (1)`).WillFail("relation \"t\" does not exist")
+	client.ExpectExecute(`ROLLBACK`)
+	db := openTestDB(t, client, func(cfg *RedshiftDataConfig) { cfg.WithSessionTransactions(true) })
+
+	_, err := execInTx(t, db, maxBatchStatements+1)
+	require.EqualError(t, err, `session transaction statement 2: query failed: relation "t" does not exist`)
+	require.NoError(t, client.ExpectationsWereMet())
+}
@@ -0,0 +1,160 @@
+package redshiftdatasqldriver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
+)
+
+// shutdownPolling is the interval at which Shutdown checks for running statements.
+const shutdownPolling = 50 * time.Millisecond
+
+// registry knows every open connection of the process, so that Shutdown can drain them.
+type registry struct {
+	mu    sync.RWMutex
+	conns map[*redshiftDataConn]struct{}
+
+	// submitMu guards the shutdown state and the count of statements being submitted,
+	// apart from mu so that slow submits do not block opening and closing connections.
+	submitMu   sync.Mutex
+	shutdown   bool
+	abandoned  bool
+	submitting int
+}
+
+var conns = &registry{}
+
+func (r *registry) add(conn *redshiftDataConn) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.conns == nil {
+		r.conns = make(map[*redshiftDataConn]struct{})
+	}
+	r.conns[conn] = struct{}{}
+}
+
+func (r *registry) remove(conn *redshiftDataConn) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.conns, conn)
+}
+
+// beginSubmit counts a statement about to be submitted, so that Shutdown waits for it.
+// It fails once Shutdown has started.
+func (r *registry) beginSubmit() error {
+	r.submitMu.Lock()
+	defer r.submitMu.Unlock()
+	if r.shutdown {
+		return ErrShutdown
+	}
+	r.submitting++
+	return nil
+}
+
+// endSubmit ends a submit counted by beginSubmit and calls track, if any, to track the submitted statement.
+// If Shutdown has stopped waiting meanwhile, track is not called and ErrShutdown is returned,
+// and the caller has to cancel the statement itself.
+func (r *registry) endSubmit(track func()) error {
+	r.submitMu.Lock()
+	defer r.submitMu.Unlock()
+	r.submitting--
+	if r.abandoned {
+		return ErrShutdown
+	}
+	if track != nil {
+		track()
+	}
+	return nil
+}
+
+// drained reports whether no statement is being submitted or running.
+func (r *registry) drained() bool {
+	r.submitMu.Lock()
+	defer r.submitMu.Unlock()
+	return r.submitting == 0 && len(r.inflight()) == 0
+}
+
+// abandon stops Shutdown from waiting and returns the statements still running.
+func (r *registry) abandon() []inflightStatement {
+	r.submitMu.Lock()
+	defer r.submitMu.Unlock()
+	r.abandoned = true
+	return r.inflight()
+}
+
+type inflightStatement struct {
+	conn *redshiftDataConn
+	id   string
+}
+
+func (r *registry) inflight() []inflightStatement {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var statements []inflightStatement
+	for conn := range r.conns {
+		conn.mu.Lock()
+		for id := range conn.inflight {
+			statements = append(statements, inflightStatement{conn: conn, id: id})
+		}
+		conn.mu.Unlock()
+	}
+	return statements
+}
+
+// Shutdown stops all connections of the process from submitting new statements,
+// which then fail with ErrShutdown, and waits for the running statements until ctx is done.
+// The statements still running then are cancelled with CancelStatement, and their IDs are returned.
+// The error joins the CancelStatement failures. Resume undoes it.
+func Shutdown(ctx context.Context) ([]string, error) {
+	conns.submitMu.Lock()
+	conns.shutdown = true
+	conns.submitMu.Unlock()
+
+	ticker := time.NewTicker(shutdownPolling)
+	defer ticker.Stop()
+	for {
+		if conns.drained() {
+			return nil, nil
+		}
+		select {
+		case <-ctx.Done():
+			return cancelStatements(conns.abandon())
+		case <-ticker.C:
+		}
+	}
+}
+
+// Resume lets the connections of the process submit statements again after Shutdown,
+// for example when a drain is called off or between tests.
+func Resume() {
+	conns.submitMu.Lock()
+	defer conns.submitMu.Unlock()
+	conns.shutdown = false
+	conns.abandoned = false
+}
+
+func cancelStatements(statements []inflightStatement) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), closeCancelTimeout)
+	defer cancel()
+	cancelled := make([]string, 0, len(statements))
+	var errs []error
+	for _, st := range statements {
+		st.conn.cfg.logDebug(ctx, "cancel statement on shutdown", slog.String("statement_id", st.id))
+		_, err := st.conn.client.CancelStatement(ctx, &redshiftdata.CancelStatementInput{
+			Id: aws.String(st.id),
+		})
+		if err != nil {
+			st.conn.cfg.logError(ctx, "failed cancel statement on shutdown", slog.String("statement_id", st.id), slog.Any("error", err))
+			errs = append(errs, fmt.Errorf("cancel statement %s: %w", st.id, err))
+			continue
+		}
+		cancelled = append(cancelled, st.id)
+	}
+	return cancelled, errors.Join(errs...)
+}
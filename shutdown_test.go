@@ -0,0 +1,148 @@
+package redshiftdatasqldriver
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShutdown(t *testing.T) {
+	defer Resume()
+	var mu sync.Mutex
+	polls := map[string]int{}
+	cancelled := map[string]bool{}
+	submitted := make(chan struct{}, 2)
+	client := &mockRedshiftDataClient{
+		ExecuteStatementFunc: func(ctx context.Context, params *redshiftdata.ExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ExecuteStatementOutput, error) {
+			select {
+			case submitted <- struct{}{}:
+			default:
+			}
+			return &redshiftdata.ExecuteStatementOutput{Id: params.Sql}, nil
+		},
+		DescribeStatementFunc: func(ctx context.Context, params *redshiftdata.DescribeStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.DescribeStatementOutput, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			polls[*params.Id]++
+			status := types.StatusStringStarted
+			switch {
+			case cancelled[*params.Id]:
+				status = types.StatusStringAborted
+			case *params.Id == "SELECT fast" && polls[*params.Id] > 3:
+				status = types.StatusStringFinished
+			}
+			return &redshiftdata.DescribeStatementOutput{
+				Id:           params.Id,
+				Status:       status,
+				Error:        aws.String("cancelled"),
+				HasResultSet: aws.Bool(false),
+			}, nil
+		},
+		CancelStatementFunc: func(ctx context.Context, params *redshiftdata.CancelStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.CancelStatementOutput, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			cancelled[*params.Id] = true
+			return &redshiftdata.CancelStatementOutput{Status: aws.Bool(true)}, nil
+		},
+	}
+	db := openTestDB(t, client, withFastPolling)
+	restore := requireNoErrorLog(t)
+	defer restore()
+
+	errs := make(map[string]error)
+	var wg sync.WaitGroup
+	for _, query := range []string{"SELECT fast", "SELECT slow"} {
+		wg.Add(1)
+		go func(query string) {
+			defer wg.Done()
+			_, err := db.ExecContext(context.Background(), query)
+			mu.Lock()
+			errs[query] = err
+			mu.Unlock()
+		}(query)
+	}
+	<-submitted
+	<-submitted
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	ids, err := Shutdown(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"SELECT slow"}, ids)
+	wg.Wait()
+	require.NoError(t, errs["SELECT fast"])
+	require.EqualError(t, errs["SELECT slow"], "query aborted: cancelled")
+
+	_, err = db.ExecContext(context.Background(), "SELECT after")
+	require.ErrorIs(t, err, ErrShutdown)
+
+	Resume()
+	_, err = db.ExecContext(context.Background(), "SELECT fast")
+	require.NoError(t, err)
+}
+
+func TestShutdownDuringSubmit(t *testing.T) {
+	defer Resume()
+	var mu sync.Mutex
+	var cancelled []string
+	submitting := make(chan struct{})
+	release := make(chan struct{})
+	client := &mockRedshiftDataClient{
+		ExecuteStatementFunc: func(ctx context.Context, params *redshiftdata.ExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ExecuteStatementOutput, error) {
+			close(submitting)
+			<-release
+			return &redshiftdata.ExecuteStatementOutput{Id: params.Sql}, nil
+		},
+		CancelStatementFunc: func(ctx context.Context, params *redshiftdata.CancelStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.CancelStatementOutput, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			cancelled = append(cancelled, *params.Id)
+			return &redshiftdata.CancelStatementOutput{Status: aws.Bool(true)}, nil
+		},
+	}
+	db := openTestDB(t, client)
+	restore := requireNoErrorLog(t)
+	defer restore()
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := db.ExecContext(context.Background(), "SELECT slow_submit")
+		errCh <- err
+	}()
+	<-submitting
+
+	// A pending submit does not block opening connections.
+	opened := make(chan error, 1)
+	go func() {
+		c, err := db.Conn(context.Background())
+		if err == nil {
+			err = c.Close()
+		}
+		opened <- err
+	}()
+	select {
+	case err := <-opened:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("opening a connection is blocked by a pending submit")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	ids, err := Shutdown(ctx)
+	require.NoError(t, err)
+	require.Empty(t, ids)
+
+	// The statement submitted after Shutdown stopped waiting cancels itself.
+	close(release)
+	require.ErrorIs(t, <-errCh, ErrShutdown)
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []string{"SELECT slow_submit"}, cancelled)
+}
@@ -0,0 +1,60 @@
+package redshiftdatasqldriver
+
+import (
+	"context"
+	"database/sql/driver"
+	"log/slog"
+)
+
+// redshiftDataStmt emulates a prepared statement on the client, since the Data API has none.
+// The query is parsed once at prepare time.
+type redshiftDataStmt struct {
+	conn  *redshiftDataConn
+	query *parsedQuery
+}
+
+func newStmt(conn *redshiftDataConn, query string) *redshiftDataStmt {
+	parsed := parseQuery(query)
+	conn.cfg.logDebug(context.Background(), "prepare statement", append([]slog.Attr{slog.Int("num_input", parsed.numInput())}, conn.cfg.sqlLogAttrs(query)...)...)
+	return &redshiftDataStmt{
+		conn:  conn,
+		query: parsed,
+	}
+}
+
+func (stmt *redshiftDataStmt) Close() error {
+	return nil
+}
+
+// NumInput returns the number of positional arguments, or -1 when the query has named placeholders,
+// in which case the arguments are checked when the statement is run.
+func (stmt *redshiftDataStmt) NumInput() int {
+	return stmt.query.numInput()
+}
+
+func (stmt *redshiftDataStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return stmt.conn.execContext(ctx, stmt.query, args)
+}
+
+func (stmt *redshiftDataStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return stmt.conn.queryContext(ctx, stmt.query, args)
+}
+
+func (stmt *redshiftDataStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return stmt.ExecContext(context.Background(), valuesToNamedValues(args))
+}
+
+func (stmt *redshiftDataStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return stmt.QueryContext(context.Background(), valuesToNamedValues(args))
+}
+
+func valuesToNamedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		named[i] = driver.NamedValue{
+			Ordinal: i + 1,
+			Value:   v,
+		}
+	}
+	return named
+}
@@ -0,0 +1,73 @@
+package redshiftdatasqldriver
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/mashiike/redshift-data-sql-driver/redshiftdatamock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNumInput(t *testing.T) {
+	cases := []struct {
+		query    string
+		expected int
+	}{
+		{query: `SELECT * FROM pg_user`, expected: 0},
+		{query: `SELECT 'hoge?' FROM pg_user WHERE usename = ? AND usesysid > ?`, expected: 2},
+		{query: `SELECT '3$1$' FROM t WHERE "$column" = $1 AND c1 > $2 AND c2 < $1`, expected: 2},
+		{query: `SELECT * FROM t WHERE id = :2`, expected: 2},
+		{query: `SELECT id::text FROM t WHERE id = ?`, expected: 1},
+		{query: `SELECT * FROM pg_user WHERE usename = :name`, expected: -1},
+		{query: `SELECT * FROM pg_user WHERE usename = @name AND usesysid > ?`, expected: -1},
+	}
+	for _, c := range cases {
+		t.Run(c.query, func(t *testing.T) {
+			require.Equal(t, c.expected, parseQuery(c.query).numInput())
+		})
+	}
+}
+
+func TestPreparedStatement(t *testing.T) {
+	client := redshiftdatamock.New()
+	client.ExpectExecute(`INSERT INTO users VALUES (:1, :2)`).WithArgs(1, "hoge").WillReturnResult(1)
+	client.ExpectExecute(`INSERT INTO users VALUES (:1, :2)`).WithArgs(2, "fuga").WillReturnResult(1)
+	client.ExpectExecute(`SELECT name FROM users WHERE id = :id`).
+		WithArgs(sql.Named("id", 1)).
+		WillReturnRows(redshiftdatamock.NewRows("name").AddRow("hoge"))
+	client.ExpectExecute(`DELETE FROM sessions`).WillReturnResult(3)
+	db := openTestDB(t, client)
+	restore := requireNoErrorLog(t)
+	defer restore()
+
+	insert, err := db.PrepareContext(context.Background(), `INSERT INTO users VALUES (?, ?)`)
+	require.NoError(t, err)
+	defer insert.Close()
+	_, err = insert.Exec(1, "hoge")
+	require.NoError(t, err)
+	_, err = insert.Exec(2, "fuga")
+	require.NoError(t, err)
+	_, err = insert.Exec(3)
+	require.EqualError(t, err, "sql: expected 2 arguments, got 1")
+
+	query, err := db.PrepareContext(context.Background(), `SELECT name FROM users WHERE id = :id`)
+	require.NoError(t, err)
+	defer query.Close()
+	var name string
+	require.NoError(t, query.QueryRow(sql.Named("id", 1)).Scan(&name))
+	require.Equal(t, "hoge", name)
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	del, err := tx.Prepare(`DELETE FROM sessions`)
+	require.NoError(t, err)
+	result, err := del.Exec()
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+	n, err := result.RowsAffected()
+	require.NoError(t, err)
+	require.EqualValues(t, 3, n)
+
+	require.NoError(t, client.ExpectationsWereMet())
+}
@@ -0,0 +1,87 @@
+package redshiftdatasqldriver
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// queryTags returns the config tags merged with the context tags.
+// When there is any tag and ctx carries a valid span, traceparent is added.
+func (cfg *RedshiftDataConfig) queryTags(ctx context.Context) map[string]string {
+	ctxTags, _ := ctx.Value(queryTagsKey{}).(map[string]string)
+	if len(cfg.QueryTags) == 0 && len(ctxTags) == 0 {
+		return nil
+	}
+	tags := make(map[string]string, len(cfg.QueryTags)+len(ctxTags)+1)
+	for k, v := range cfg.QueryTags {
+		tags[k] = v
+	}
+	for k, v := range ctxTags {
+		tags[k] = v
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		if _, ok := tags["traceparent"]; !ok {
+			tags["traceparent"] = fmt.Sprintf("00-%s-%s-%s", sc.TraceID(), sc.SpanID(), sc.TraceFlags())
+		}
+	}
+	return tags
+}
+
+// tagSQL appends the sqlcommenter comment built from the query tags to sql.
+func (cfg *RedshiftDataConfig) tagSQL(ctx context.Context, sql string) string {
+	return appendQueryComment(sql, cfg.queryTags(ctx))
+}
+
+// queryCommentSize returns the bytes tagSQL adds under ctx to SQL without a comment.
+// Room is kept for a traceparent when a tracer provider is set, since the span that
+// supplies it is started after the size is checked.
+func (cfg *RedshiftDataConfig) queryCommentSize(ctx context.Context) int {
+	tags := cfg.queryTags(ctx)
+	if _, ok := tags["traceparent"]; len(tags) > 0 && !ok && cfg.TracerProvider != nil {
+		tags["traceparent"] = fmt.Sprintf("00-%s-%s-%s", trace.TraceID{}, trace.SpanID{}, trace.TraceFlags(0))
+	}
+	return len(appendQueryComment("-", tags)) - 1
+}
+
+// taggedSize returns a func measuring SQL as it is sent, with the comment tagSQL appends under ctx.
+func (cfg *RedshiftDataConfig) taggedSize(ctx context.Context) func(sql string) int {
+	commentSize := cfg.queryCommentSize(ctx)
+	return func(sql string) int {
+		if commentSize == 0 || hasComment(sql) {
+			return len(sql)
+		}
+		return len(sql) + commentSize
+	}
+}
+
+// appendQueryComment appends a sqlcommenter comment such as /*app='api',route='%2Fusers'*/.
+// As the sqlcommenter spec requires, SQL that already has a comment is left as is.
+func appendQueryComment(sql string, tags map[string]string) string {
+	if len(tags) == 0 || hasComment(sql) {
+		return sql
+	}
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s='%s'", sqlcommenterEscape(k), sqlcommenterEscape(tags[k])))
+	}
+	comment := "/*" + strings.Join(pairs, ",") + "*/"
+	trimmed := strings.TrimRight(sql, " \t\r\n")
+	if strings.HasSuffix(trimmed, ";") {
+		return strings.TrimSuffix(trimmed, ";") + " " + comment + ";"
+	}
+	return trimmed + " " + comment
+}
+
+func sqlcommenterEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
@@ -0,0 +1,148 @@
+package redshiftdatasqldriver
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestAppendQueryComment(t *testing.T) {
+	cases := []struct {
+		name     string
+		sql      string
+		tags     map[string]string
+		expected string
+	}{
+		{
+			name:     "no tags",
+			sql:      "SELECT 1",
+			expected: "SELECT 1",
+		},
+		{
+			name:     "sorted and escaped",
+			sql:      "SELECT * FROM users",
+			tags:     map[string]string{"route": "/users/{id}", "app": "api server", "quote": "it's"},
+			expected: "SELECT * FROM users /*app='api%20server',quote='it%27s',route='%2Fusers%2F%7Bid%7D'*/",
+		},
+		{
+			name:     "before trailing semicolon",
+			sql:      "SELECT 1;\n",
+			tags:     map[string]string{"app": "api"},
+			expected: "SELECT 1 /*app='api'*/;",
+		},
+		{
+			name:     "existing comment",
+			sql:      "SELECT 1 /* keep */",
+			tags:     map[string]string{"app": "api"},
+			expected: "SELECT 1 /* keep */",
+		},
+		{
+			name:     "existing line comment",
+			sql:      "SELECT 1 -- keep",
+			tags:     map[string]string{"app": "api"},
+			expected: "SELECT 1 -- keep",
+		},
+		{
+			name:     "comment markers in literal and identifier",
+			sql:      `SELECT "a--b" FROM notes WHERE note = 'a--b' OR note = '/*'`,
+			tags:     map[string]string{"app": "api"},
+			expected: `SELECT "a--b" FROM notes WHERE note = 'a--b' OR note = '/*' /*app='api'*/`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			require.Equal(t, c.expected, appendQueryComment(c.sql, c.tags))
+		})
+	}
+}
+
+func TestQueryTagging(t *testing.T) {
+	var inputs []*redshiftdata.ExecuteStatementInput
+	var batchInputs []*redshiftdata.BatchExecuteStatementInput
+	client := &mockRedshiftDataClient{
+		ExecuteStatementFunc: func(ctx context.Context, params *redshiftdata.ExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ExecuteStatementOutput, error) {
+			inputs = append(inputs, params)
+			return &redshiftdata.ExecuteStatementOutput{Id: aws.String("id")}, nil
+		},
+		BatchExecuteStatementFunc: func(ctx context.Context, params *redshiftdata.BatchExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.BatchExecuteStatementOutput, error) {
+			batchInputs = append(batchInputs, params)
+			return &redshiftdata.BatchExecuteStatementOutput{Id: aws.String("batch")}, nil
+		},
+		DescribeStatementFunc: func(ctx context.Context, params *redshiftdata.DescribeStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.DescribeStatementOutput, error) {
+			return &redshiftdata.DescribeStatementOutput{
+				Id:           params.Id,
+				Status:       types.StatusStringFinished,
+				HasResultSet: aws.Bool(false),
+				SubStatements: []types.SubStatementData{
+					{Id: aws.String("batch:1"), HasResultSet: aws.Bool(false)},
+					{Id: aws.String("batch:2"), HasResultSet: aws.Bool(false)},
+				},
+			}, nil
+		},
+	}
+	db := openTestDB(t, client, func(cfg *RedshiftDataConfig) {
+		cfg.WithStatementName("api").WithQueryTags(map[string]string{"app": "api"})
+	})
+	restore := requireNoErrorLog(t)
+	defer restore()
+
+	_, err := db.ExecContext(context.Background(), `DELETE FROM sessions`)
+	require.NoError(t, err)
+	ctx := WithStatementName(context.Background(), "GET /users")
+	ctx = WithQueryTags(ctx, map[string]string{"route": "/users"})
+	_, err = db.ExecContext(ctx, `DELETE FROM users`)
+	require.NoError(t, err)
+
+	require.Len(t, inputs, 2)
+	require.Equal(t, "DELETE FROM sessions /*app='api'*/", *inputs[0].Sql)
+	require.Equal(t, "api", *inputs[0].StatementName)
+	require.Equal(t, "DELETE FROM users /*app='api',route='%2Fusers'*/", *inputs[1].Sql)
+	require.Equal(t, "GET /users", *inputs[1].StatementName)
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	_, err = tx.ExecContext(ctx, `INSERT INTO foo VALUES (1)`)
+	require.NoError(t, err)
+	_, err = tx.ExecContext(ctx, `INSERT INTO foo VALUES (2)`)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+	require.Len(t, batchInputs, 1)
+	require.Equal(t, []string{
+		"INSERT INTO foo VALUES (1) /*app='api',route='%2Fusers'*/",
+		"INSERT INTO foo VALUES (2) /*app='api',route='%2Fusers'*/",
+	}, batchInputs[0].Sqls)
+	require.Equal(t, "GET /users", *batchInputs[0].StatementName)
+}
+
+func TestQueryTaggingTraceparent(t *testing.T) {
+	var input *redshiftdata.ExecuteStatementInput
+	client := &mockRedshiftDataClient{
+		ExecuteStatementFunc: func(ctx context.Context, params *redshiftdata.ExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ExecuteStatementOutput, error) {
+			input = params
+			return &redshiftdata.ExecuteStatementOutput{Id: aws.String("id")}, nil
+		},
+		DescribeStatementFunc: func(ctx context.Context, params *redshiftdata.DescribeStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.DescribeStatementOutput, error) {
+			return &redshiftdata.DescribeStatementOutput{
+				Id:           params.Id,
+				Status:       types.StatusStringFinished,
+				HasResultSet: aws.Bool(false),
+			}, nil
+		},
+	}
+	db := openTestDB(t, client, func(cfg *RedshiftDataConfig) {
+		cfg.WithQueryTags(map[string]string{"app": "api"}).WithTracerProvider(sdktrace.NewTracerProvider())
+	})
+	restore := requireNoErrorLog(t)
+	defer restore()
+
+	_, err := db.ExecContext(context.Background(), `DELETE FROM sessions`)
+	require.NoError(t, err)
+	require.Regexp(t, regexp.MustCompile(`^DELETE FROM sessions /\*app='api',traceparent='00-[0-9a-f]{32}-[0-9a-f]{16}-01'\*/$`), *input.Sql)
+	require.Nil(t, input.StatementName)
+}
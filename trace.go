@@ -0,0 +1,77 @@
+package redshiftdatasqldriver
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const tracerName = "github.com/mashiike/redshift-data-sql-driver"
+
+func (cfg *RedshiftDataConfig) tracer() trace.Tracer {
+	if cfg != nil && cfg.TracerProvider != nil {
+		return cfg.TracerProvider.Tracer(tracerName)
+	}
+	return noop.NewTracerProvider().Tracer(tracerName)
+}
+
+// startSpan starts the span covering one statement lifecycle.
+// Events for submit, poll and page fetch are added to it through the returned context.
+func (cfg *RedshiftDataConfig) startSpan(ctx context.Context, operation string, sql string) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		attribute.String("db.system", "redshift"),
+		attribute.String("db.operation", operation),
+	}
+	if database := cfg.database(ctx); database != nil {
+		attrs = append(attrs, attribute.String("db.name", *database))
+	}
+	if cfg.WorkgroupName != nil {
+		attrs = append(attrs, attribute.String("db.redshift.workgroup_name", *cfg.WorkgroupName))
+	}
+	if cfg.ClusterIdentifier != nil {
+		attrs = append(attrs, attribute.String("db.redshift.cluster_identifier", *cfg.ClusterIdentifier))
+	}
+	if s, ok := cfg.loggableSQL(sql); ok && sql != "" {
+		attrs = append(attrs, attribute.String("db.statement", s))
+	}
+	return cfg.tracer().Start(ctx, "redshift-data."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+func addSpanEvent(ctx context.Context, name string, attrs ...attribute.KeyValue) {
+	trace.SpanFromContext(ctx).AddEvent(name, trace.WithAttributes(attrs...))
+}
+
+func describeSpanAttributes(desc *redshiftdata.DescribeStatementOutput) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("db.redshift.statement_id", coalesce(desc.Id)),
+		attribute.Int64("db.redshift.query_id", desc.RedshiftQueryId),
+		attribute.String("db.redshift.status", string(desc.Status)),
+	}
+}
+
+func setSpanResult(span trace.Span, desc *redshiftdata.DescribeStatementOutput) {
+	span.SetAttributes(describeSpanAttributes(desc)...)
+	span.SetAttributes(
+		attribute.Int64("db.redshift.result_rows", desc.ResultRows),
+		attribute.Int64("db.redshift.result_size", desc.ResultSize),
+	)
+}
+
+// endSpan records the outcome of the statement on span and ends it.
+func endSpan(span trace.Span, desc *redshiftdata.DescribeStatementOutput, err error) {
+	if desc != nil {
+		setSpanResult(span, desc)
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,101 @@
+package redshiftdatasqldriver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftdata/types"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracing(t *testing.T) {
+	var lastStatus types.StatusString
+	client := &mockRedshiftDataClient{
+		ExecuteStatementFunc: func(ctx context.Context, params *redshiftdata.ExecuteStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.ExecuteStatementOutput, error) {
+			lastStatus = ""
+			return &redshiftdata.ExecuteStatementOutput{
+				Id: aws.String(coalesce(params.Sql)),
+			}, nil
+		},
+		DescribeStatementFunc: func(ctx context.Context, params *redshiftdata.DescribeStatementInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.DescribeStatementOutput, error) {
+			if lastStatus == "" {
+				lastStatus = types.StatusStringStarted
+			} else {
+				lastStatus = types.StatusStringFinished
+			}
+			if *params.Id == "SELECT broken" && lastStatus == types.StatusStringFinished {
+				return &redshiftdata.DescribeStatementOutput{
+					Id:     params.Id,
+					Status: types.StatusStringFailed,
+					Error:  aws.String("syntax error"),
+				}, nil
+			}
+			return &redshiftdata.DescribeStatementOutput{
+				Id:              params.Id,
+				Status:          lastStatus,
+				HasResultSet:    aws.Bool(*params.Id == "SELECT 1"),
+				RedshiftQueryId: 42,
+				ResultRows:      1,
+				ResultSize:      8,
+			}, nil
+		},
+		GetStatementResultFunc: func(ctx context.Context, params *redshiftdata.GetStatementResultInput, optFns ...func(*redshiftdata.Options)) (*redshiftdata.GetStatementResultOutput, error) {
+			return &redshiftdata.GetStatementResultOutput{
+				ColumnMetadata: []types.ColumnMetadata{{Name: aws.String("?column?"), TypeName: aws.String("int4")}},
+				Records:        [][]types.Field{{&types.FieldMemberLongValue{Value: 1}}},
+				TotalNumRows:   1,
+			}, nil
+		},
+	}
+	recorder := tracetest.NewSpanRecorder()
+	db := openTestDB(t, client, func(cfg *RedshiftDataConfig) {
+		cfg.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	})
+	restore := requireNoErrorLog(t)
+	defer restore()
+
+	var n int64
+	require.NoError(t, db.QueryRowContext(context.Background(), `SELECT 1`).Scan(&n))
+	_, err := db.ExecContext(WithDatabase(context.Background(), "analytics"), `SELECT broken`)
+	require.Error(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	query := spans[0]
+	require.Equal(t, "redshift-data.ExecuteStatement", query.Name())
+	attrs := attribute.NewSet(query.Attributes()...)
+	for key, expected := range map[attribute.Key]attribute.Value{
+		"db.system":                attribute.StringValue("redshift"),
+		"db.name":                  attribute.StringValue("dev"),
+		"db.statement":             attribute.StringValue("SELECT 1"),
+		"db.redshift.statement_id": attribute.StringValue("SELECT 1"),
+		"db.redshift.query_id":     attribute.Int64Value(42),
+		"db.redshift.result_rows":  attribute.Int64Value(1),
+		"db.redshift.result_size":  attribute.Int64Value(8),
+		"db.redshift.status":       attribute.StringValue("FINISHED"),
+	} {
+		actual, ok := attrs.Value(key)
+		require.True(t, ok, key)
+		require.Equal(t, expected, actual, key)
+	}
+	var events []string
+	for _, event := range query.Events() {
+		events = append(events, event.Name)
+	}
+	require.Equal(t, []string{"submit", "poll", "poll", "page fetch"}, events)
+
+	failed := spans[1]
+	require.Equal(t, codes.Error, failed.Status().Code)
+	require.Contains(t, failed.Status().Description, "syntax error")
+	failedAttrs := attribute.NewSet(failed.Attributes()...)
+	database, ok := failedAttrs.Value("db.name")
+	require.True(t, ok)
+	require.Equal(t, "analytics", database.AsString())
+}
@@ -1,16 +1,29 @@
 package redshiftdatasqldriver
 
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
 type redshiftDataTx struct {
+	cfg        *RedshiftDataConfig
 	onCommit   func() error
 	onRollback func() error
 }
 
 func (tx *redshiftDataTx) Commit() error {
-	debugLogger.Printf("tx commit called")
-	return tx.onCommit()
+	start := time.Now()
+	err := tx.onCommit()
+	attrs := []slog.Attr{elapsedAttr(start)}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	tx.cfg.logDebug(context.Background(), "tx commit", attrs...)
+	return err
 }
 
 func (tx *redshiftDataTx) Rollback() error {
-	debugLogger.Printf("tx rollback called")
+	tx.cfg.logDebug(context.Background(), "tx rollback")
 	return tx.onRollback()
 }